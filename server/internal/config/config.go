@@ -0,0 +1,271 @@
+// internal/config/config.go
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"royaka/internal/model"
+)
+
+// DefaultFile is read when ROYAKA_CONFIG does not name another file. It is
+// optional; without it the defaults and environment are used.
+const DefaultFile = "config.json"
+
+// Config holds the server settings. Each one can be set in the config file
+// and overridden by its environment variable.
+type Config struct {
+	ListenAddr       string                     `json:"listen_addr"`       // ROYAKA_LISTEN_ADDR, or PORT
+	DataDir          string                     `json:"data_dir"`          // ROYAKA_DATA_DIR
+	Storage          string                     `json:"storage"`           // ROYAKA_STORAGE
+	AllowedOrigins   []string                   `json:"allowed_origins"`   // ROYAKA_ALLOWED_ORIGINS, comma separated
+	TickRate         model.Duration             `json:"tick_rate"`         // ROYAKA_TICK_RATE
+	LogLevel         string                     `json:"log_level"`         // ROYAKA_LOG_LEVEL
+	LogFormat        string                     `json:"log_format"`        // ROYAKA_LOG_FORMAT
+	ShutdownGrace    model.Duration             `json:"shutdown_grace"`    // ROYAKA_SHUTDOWN_GRACE
+	SnapshotInterval model.Duration             `json:"snapshot_interval"` // ROYAKA_SNAPSHOT_INTERVAL, 0 disables
+	AdminToken       string                     `json:"admin_token"`       // ROYAKA_ADMIN_TOKEN, empty disables /admin/
+	TrustedProxies   []string                   `json:"trusted_proxies"`   // ROYAKA_TRUSTED_PROXIES, comma separated IPs or CIDRs
+	GuestTTL         model.Duration             `json:"guest_ttl"`         // ROYAKA_GUEST_TTL, 0 keeps guests forever
+	MatchRules       map[string]json.RawMessage `json:"match_rules"`       // per mode, over the defaults
+
+	// Rules is MatchRules resolved against the defaults by Validate.
+	Rules map[string]model.MatchRules `json:"-"`
+	// Proxies is TrustedProxies parsed by Validate.
+	Proxies []netip.Prefix `json:"-"`
+}
+
+// Storage backends the server can run with.
+var storages = []string{"json"}
+
+// minAdminToken is the shortest admin token accepted.
+const minAdminToken = 16
+
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"text", "json"}
+)
+
+func Default() Config {
+	return Config{
+		ListenAddr:       ":8080",
+		DataDir:          "assets/data",
+		Storage:          "json",
+		TickRate:         model.Duration(100 * time.Millisecond),
+		LogLevel:         "info",
+		LogFormat:        "text",
+		ShutdownGrace:    model.Duration(30 * time.Second),
+		SnapshotInterval: model.Duration(5 * time.Second),
+		GuestTTL:         model.Duration(7 * 24 * time.Hour),
+	}
+}
+
+// Load reads the config file named by ROYAKA_CONFIG (or DefaultFile if it
+// exists), applies environment overrides and validates the result.
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, explicit := os.LookupEnv("ROYAKA_CONFIG")
+	if !explicit {
+		path = DefaultFile
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("config %s: %w", path, err)
+		}
+	case !os.IsNotExist(err) || explicit:
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) applyEnv() error {
+	if port := os.Getenv("PORT"); port != "" {
+		c.ListenAddr = ":" + port
+	}
+	if v := os.Getenv("ROYAKA_LISTEN_ADDR"); v != "" {
+		c.ListenAddr = v
+	}
+	if v := os.Getenv("ROYAKA_DATA_DIR"); v != "" {
+		c.DataDir = v
+	}
+	if v := os.Getenv("ROYAKA_STORAGE"); v != "" {
+		c.Storage = v
+	}
+	if v := os.Getenv("ROYAKA_ALLOWED_ORIGINS"); v != "" {
+		c.AllowedOrigins = nil
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.AllowedOrigins = append(c.AllowedOrigins, origin)
+			}
+		}
+	}
+	if v := os.Getenv("ROYAKA_TRUSTED_PROXIES"); v != "" {
+		c.TrustedProxies = nil
+		for _, proxy := range strings.Split(v, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				c.TrustedProxies = append(c.TrustedProxies, proxy)
+			}
+		}
+	}
+	if v := os.Getenv("ROYAKA_TICK_RATE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("ROYAKA_TICK_RATE: %w", err)
+		}
+		c.TickRate = model.Duration(d)
+	}
+	if v := os.Getenv("ROYAKA_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv("ROYAKA_LOG_FORMAT"); v != "" {
+		c.LogFormat = v
+	}
+	if v := os.Getenv("ROYAKA_SHUTDOWN_GRACE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("ROYAKA_SHUTDOWN_GRACE: %w", err)
+		}
+		c.ShutdownGrace = model.Duration(d)
+	}
+	if v := os.Getenv("ROYAKA_ADMIN_TOKEN"); v != "" {
+		c.AdminToken = v
+	}
+	if v := os.Getenv("ROYAKA_SNAPSHOT_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("ROYAKA_SNAPSHOT_INTERVAL: %w", err)
+		}
+		c.SnapshotInterval = model.Duration(d)
+	}
+	if v := os.Getenv("ROYAKA_GUEST_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("ROYAKA_GUEST_TTL: %w", err)
+		}
+		c.GuestTTL = model.Duration(d)
+	}
+	return nil
+}
+
+// Validate checks every setting and resolves the match rules, reporting
+// all problems at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+		errs = append(errs, fmt.Errorf("listen_addr %q: %w", c.ListenAddr, err))
+	}
+
+	if c.DataDir == "" {
+		errs = append(errs, errors.New("data_dir is required"))
+	} else if info, err := os.Stat(c.DataDir); err != nil || !info.IsDir() {
+		errs = append(errs, fmt.Errorf("data_dir %q is not a directory", c.DataDir))
+	}
+
+	if !slices.Contains(storages, c.Storage) {
+		errs = append(errs, fmt.Errorf("storage %q is not supported (use %s)", c.Storage, strings.Join(storages, ", ")))
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("allowed origin %q must start with http:// or https://", origin))
+		}
+	}
+
+	c.Proxies = nil
+	for _, proxy := range c.TrustedProxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(proxy)
+			if addrErr != nil {
+				errs = append(errs, fmt.Errorf("trusted proxy %q is not an IP address or CIDR", proxy))
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		c.Proxies = append(c.Proxies, prefix.Masked())
+	}
+
+	if tick := time.Duration(c.TickRate); tick < 10*time.Millisecond || tick > time.Second {
+		errs = append(errs, fmt.Errorf("tick_rate %s must be between 10ms and 1s", tick))
+	}
+
+	if grace := time.Duration(c.ShutdownGrace); grace < 0 || grace > 10*time.Minute {
+		errs = append(errs, fmt.Errorf("shutdown_grace %s must be between 0 and 10m", grace))
+	}
+
+	if every := time.Duration(c.SnapshotInterval); every != 0 && (every < time.Second || every > 5*time.Minute) {
+		errs = append(errs, fmt.Errorf("snapshot_interval %s must be 0 or between 1s and 5m", every))
+	}
+
+	if ttl := time.Duration(c.GuestTTL); ttl != 0 && ttl < time.Hour {
+		errs = append(errs, fmt.Errorf("guest_ttl %s must be 0 or at least 1h", ttl))
+	}
+
+	if c.AdminToken != "" && len(c.AdminToken) < minAdminToken {
+		errs = append(errs, fmt.Errorf("admin_token must be at least %d characters", minAdminToken))
+	}
+
+	c.LogLevel = strings.ToLower(c.LogLevel)
+	if !slices.Contains(logLevels, c.LogLevel) {
+		errs = append(errs, fmt.Errorf("log_level %q must be one of %s", c.LogLevel, strings.Join(logLevels, ", ")))
+	}
+	c.LogFormat = strings.ToLower(c.LogFormat)
+	if !slices.Contains(logFormats, c.LogFormat) {
+		errs = append(errs, fmt.Errorf("log_format %q must be one of %s", c.LogFormat, strings.Join(logFormats, ", ")))
+	}
+
+	// Without rules in the config, the data directory's match_rules.json
+	// is used if there is one
+	source := "match_rules"
+	if c.MatchRules == nil && c.DataDir != "" {
+		source = filepath.Join(c.DataDir, "match_rules.json")
+		if data, err := os.ReadFile(source); err == nil {
+			if err := json.Unmarshal(data, &c.MatchRules); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			}
+		} else if !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+		}
+	}
+	rules, err := model.ParseMatchRules(c.MatchRules)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", source, err))
+	}
+	c.Rules = rules
+
+	return errors.Join(errs...)
+}
+
+// AllowsOrigin reports whether a WebSocket handshake from origin is allowed.
+// No configured origins, or "*", allows any.
+func (c *Config) AllowsOrigin(origin string) bool {
+	if len(c.AllowedOrigins) == 0 || origin == "" {
+		return true
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
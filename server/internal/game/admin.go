@@ -0,0 +1,249 @@
+package game
+
+import (
+	"cmp"
+	"errors"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrRoomNotFound  = errors.New("room not found")
+	ErrMatchOver     = errors.New("match already ended")
+	ErrNotInRoom     = errors.New("user is not a player in this room")
+	ErrNotConnected  = errors.New("user is not connected")
+	announcementKind = []string{"info", "warning", "maintenance"}
+)
+
+// queueEntry records when a player started looking for a match.
+type queueEntry struct {
+	Mode  string
+	Since time.Time
+}
+
+// RoomSummary describes a room for the admin console.
+type RoomSummary struct {
+	RoomID     string          `json:"room_id"`
+	Mode       string          `json:"mode"`
+	Phase      string          `json:"phase,omitempty"`
+	Players    []PlayerSummary `json:"players"`
+	Elapsed    float64         `json:"elapsed_seconds"`
+	Ended      bool            `json:"ended"`
+	Paused     bool            `json:"paused"`
+	Spectators int             `json:"spectators"`
+}
+
+type PlayerSummary struct {
+	Username        string `json:"username"`
+	Connected       bool   `json:"connected"`
+	Mana            int    `json:"mana"`
+	TowersDestroyed int    `json:"towers_destroyed"` // of the opponent's
+}
+
+type QueuedPlayer struct {
+	Username string  `json:"username"`
+	Waiting  float64 `json:"waiting_seconds"`
+}
+
+// ==== ROOMS ====
+
+// RoomSummaries lists every room, oldest match first.
+func RoomSummaries() []RoomSummary {
+	roomsMu.RLock()
+	list := make([]*Room, 0, len(rooms))
+	for _, room := range rooms {
+		if room.Game != nil {
+			list = append(list, room)
+		}
+	}
+	roomsMu.RUnlock()
+
+	summaries := make([]RoomSummary, 0, len(list))
+	for _, room := range list {
+		g := room.Game
+		g.replay.mu.Lock()
+		started := g.replay.start
+		g.replay.mu.Unlock()
+
+		summary := RoomSummary{
+			RoomID:     room.ID,
+			Mode:       g.Mode(),
+			Elapsed:    time.Since(started).Round(time.Second).Seconds(),
+			Ended:      g.WinnerDeclared,
+			Paused:     g.Paused,
+			Spectators: SpectatorCount(room.ID),
+		}
+		if g.Enhanced {
+			summary.Phase = g.Phase
+		}
+		for _, p := range []*model.Player{g.Player1, g.Player2} {
+			summary.Players = append(summary.Players, PlayerSummary{
+				Username:        p.User.Username,
+				Connected:       isConnected(p.User.Username),
+				Mana:            p.Mana,
+				TowersDestroyed: g.Opponent(p).DestroyedCount(),
+			})
+		}
+		summaries = append(summaries, summary)
+	}
+
+	slices.SortFunc(summaries, func(a, b RoomSummary) int {
+		return cmp.Compare(b.Elapsed, a.Elapsed)
+	})
+	return summaries
+}
+
+// ForceEnd ends a running match. With a winner the match counts as won by
+// that player, otherwise it is a draw; either way it is recorded with the
+// admin end reason.
+func ForceEnd(roomID, winnerName string) error {
+	roomsMu.RLock()
+	room, ok := rooms[roomID]
+	roomsMu.RUnlock()
+	if !ok || room.Game == nil {
+		return ErrRoomNotFound
+	}
+
+	g := room.Game
+	if winnerName == "" {
+		if !g.abort(model.EndAdmin, "Match ended by an administrator") {
+			return ErrMatchOver
+		}
+		return nil
+	}
+
+	winner := g.Player1
+	if g.Player2.User.Username == winnerName {
+		winner = g.Player2
+	} else if g.Player1.User.Username != winnerName {
+		return ErrNotInRoom
+	}
+
+	// Under the lock the match is played under, so the game loop or a turn
+	// cannot end it at the same time
+	unlock := g.lockMatch()
+	if g.WinnerDeclared {
+		unlock()
+		return ErrMatchOver
+	}
+	g.WinnerDeclared = true
+	g.EndReason = model.EndAdmin
+	g.Paused = false
+	g.StopGameLoop()
+	if g.TurnTimerCancel != nil {
+		g.TurnTimerCancel()
+	}
+	g.AwardEXP(winner, g.Opponent(winner), false)
+	unlock()
+
+	log.Printf("[INFO][ADMIN] Ended match in room %s, %s wins", roomID, winnerName)
+	g.broadcast(utils.Response{
+		Type:    "game_over_response",
+		Success: true,
+		Message: "Match ended by an administrator, " + winnerName + " wins",
+		Data: map[string]interface{}{
+			"winner": winner,
+		},
+	})
+	return nil
+}
+
+// ==== USERS ====
+
+func isConnected(username string) bool {
+	clientsMu.RLock()
+	defer clientsMu.RUnlock()
+	client, ok := clients[username]
+	return ok && client.Conn != nil
+}
+
+// KickUser tells the user why and closes their connection. Leaving through
+// the normal disconnect path forfeits any match they are in.
+func KickUser(username, reason string) error {
+	clientsMu.RLock()
+	client, ok := clients[username]
+	clientsMu.RUnlock()
+	if !ok || client.Conn == nil {
+		return ErrNotConnected
+	}
+
+	client.SafeWrite(utils.Response{
+		Type:    "kicked",
+		Success: false,
+		Message: reason,
+	})
+
+	closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "kicked")
+	client.Mu.Lock()
+	client.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+	client.Conn.Close()
+	client.Mu.Unlock()
+
+	log.Printf("[INFO][ADMIN] Kicked %s: %s", username, reason)
+	return nil
+}
+
+// ==== QUEUES ====
+
+// QueueContents lists the players looking for a match per mode, longest
+// waiting first.
+func QueueContents() map[string][]QueuedPlayer {
+	contents := make(map[string][]QueuedPlayer, len(matchQueues))
+	for mode := range matchQueues {
+		contents[mode] = []QueuedPlayer{}
+	}
+
+	pendingMu.RLock()
+	for username, entry := range pendingPlayers {
+		contents[entry.Mode] = append(contents[entry.Mode], QueuedPlayer{
+			Username: username,
+			Waiting:  time.Since(entry.Since).Round(time.Second).Seconds(),
+		})
+	}
+	pendingMu.RUnlock()
+
+	for _, queued := range contents {
+		slices.SortFunc(queued, func(a, b QueuedPlayer) int {
+			return cmp.Compare(b.Waiting, a.Waiting)
+		})
+	}
+	return contents
+}
+
+// ==== ANNOUNCEMENTS ====
+
+// ValidAnnouncementKind reports whether kind is an announcement kind
+// clients know how to show.
+func ValidAnnouncementKind(kind string) bool {
+	return slices.Contains(announcementKind, kind)
+}
+
+// Announce pushes a server announcement to every connected client and
+// returns how many were sent.
+func Announce(message, kind string) int {
+	clientsMu.RLock()
+	usernames := make([]string, 0, len(clients))
+	for username := range clients {
+		usernames = append(usernames, username)
+	}
+	clientsMu.RUnlock()
+
+	for _, username := range usernames {
+		sendToClient(username, utils.Response{
+			Type:    "server_announcement",
+			Success: true,
+			Message: message,
+			Data: map[string]interface{}{
+				"kind": kind,
+				"at":   time.Now(),
+			},
+		})
+	}
+	log.Printf("[INFO][ADMIN] Announcement (%s) sent to %d clients: %s", kind, len(usernames), message)
+	return len(usernames)
+}
@@ -0,0 +1,335 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+	"royaka/internal/utils"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxChatLength = 120
+	chatCooldown  = 1 * time.Second
+	emoteCooldown = 3 * time.Second
+	lobbyChannel  = "lobby"
+	roomChannel   = "room"
+)
+
+// Quick emotes usable mid-battle.
+var Emotes = []string{"thumbs_up", "laughing", "crying", "angry", "good_game", "well_played", "oops", "thanks"}
+
+var profanityPattern = regexp.MustCompile(`(?i)\b(fuck\w*|shit\w*|bitch\w*|asshole\w*|bastard\w*|cunt\w*|whore\w*|slut\w*|retard\w*|vcl|clm|dcm)\b`)
+
+type ChatMessage struct {
+	From      string    `json:"from"`
+	Channel   string    `json:"channel"`
+	Message   string    `json:"message,omitempty"`
+	Emote     string    `json:"emote,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+var (
+	lastChatAt  = make(map[string]time.Time)
+	lastEmoteAt = make(map[string]time.Time)
+	// username -> usernames they muted
+	mutedBy = make(map[string]map[string]bool)
+	chatMu  sync.Mutex
+)
+
+func HandleChat(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChatRequest
+
+	if err := json.Unmarshal(data, &req); err != nil {
+		log.Printf("[WARN][CHAT] invalid request: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "chat_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	sender := ClientUsername(conn)
+	if sender == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "chat_response",
+			Success: false,
+			Message: "Login required to chat",
+		})
+		return
+	}
+
+	text := strings.TrimSpace(req.Message)
+	if text == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "chat_response",
+			Success: false,
+			Message: "Message is empty",
+		})
+		return
+	}
+	if utf8.RuneCountInString(text) > maxChatLength {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "chat_response",
+			Success: false,
+			Message: "Message is too long",
+		})
+		return
+	}
+
+	if !takeCooldown(lastChatAt, sender, chatCooldown) {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "chat_response",
+			Success: false,
+			Message: "You are sending messages too fast",
+		})
+		return
+	}
+
+	msg := ChatMessage{
+		From:      sender,
+		Message:   filterProfanity(text),
+		Timestamp: time.Now(),
+	}
+
+	if req.RoomID == "" {
+		msg.Channel = lobbyChannel
+		deliverChat(lobbyRecipients(), msg)
+	} else {
+		roomsMu.RLock()
+		room, exists := rooms[req.RoomID]
+		roomsMu.RUnlock()
+		if !exists {
+			utils.WriteMessage(conn, utils.Response{
+				Type:    "chat_response",
+				Success: false,
+				Message: roomRequestMessage,
+			})
+			return
+		}
+
+		msg.Channel = roomChannel
+		recipients, ok := room.chatRecipients(sender)
+		if !ok {
+			utils.WriteMessage(conn, utils.Response{
+				Type:    "chat_response",
+				Success: false,
+				Message: "You are not part of this match",
+			})
+			return
+		}
+		room.recordChat(msg)
+		deliverChat(recipients, msg)
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "chat_response",
+		Success: true,
+		Message: "Message sent",
+	})
+}
+
+func HandleEmote(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.EmoteRequest
+
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Emote == "" {
+		log.Printf("[WARN][EMOTE] invalid request: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "emote_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	if !slices.Contains(Emotes, req.Emote) {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "emote_response",
+			Success: false,
+			Message: "Unknown emote",
+		})
+		return
+	}
+
+	roomsMu.RLock()
+	room, exists := rooms[req.RoomID]
+	roomsMu.RUnlock()
+	if !exists {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "emote_response",
+			Success: false,
+			Message: roomRequestMessage,
+		})
+		return
+	}
+
+	sender := ClientUsername(conn)
+	if sender == "" || (room.Player1.User.Username != sender && room.Player2.User.Username != sender) {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "emote_response",
+			Success: false,
+			Message: "Only players can use emotes",
+		})
+		return
+	}
+
+	if !takeCooldown(lastEmoteAt, sender, emoteCooldown) {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "emote_response",
+			Success: false,
+			Message: "Emote is on cooldown",
+		})
+		return
+	}
+
+	msg := ChatMessage{
+		From:      sender,
+		Channel:   roomChannel,
+		Emote:     req.Emote,
+		Timestamp: time.Now(),
+	}
+	recipients, _ := room.chatRecipients(sender)
+	room.recordChat(msg)
+	deliverChat(recipients, msg)
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "emote_response",
+		Success: true,
+		Message: "Emote sent",
+	})
+}
+
+func HandleToggleMute(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.MuteRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || req.Target == "" || username == "" || req.Target == username {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "mute_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	chatMu.Lock()
+	if mutedBy[username] == nil {
+		mutedBy[username] = make(map[string]bool)
+	}
+	muted := !mutedBy[username][req.Target]
+	if muted {
+		mutedBy[username][req.Target] = true
+	} else {
+		delete(mutedBy[username], req.Target)
+	}
+	chatMu.Unlock()
+
+	log.Printf("[INFO][CHAT] %s set mute=%v for %s", username, muted, req.Target)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "mute_response",
+		Success: true,
+		Message: "Mute updated",
+		Data: map[string]interface{}{
+			"target": req.Target,
+			"muted":  muted,
+		},
+	})
+}
+
+// chatRecipients returns who should see a room message from sender. Player
+// messages reach everyone; spectator messages stay among spectators so they
+// cannot pass information to the players.
+func (r *Room) chatRecipients(sender string) ([]string, bool) {
+	spectatorsMu.RLock()
+	var viewers []string
+	for username := range spectators[r.ID] {
+		viewers = append(viewers, username)
+	}
+	_, isViewer := spectators[r.ID][sender]
+	spectatorsMu.RUnlock()
+
+	if r.Player1.User.Username == sender || r.Player2.User.Username == sender {
+		return append([]string{r.Player1.User.Username, r.Player2.User.Username}, viewers...), true
+	}
+	if isViewer {
+		return viewers, true
+	}
+	return nil, false
+}
+
+// recordChat keeps the room's chat history so it can be stored with the match.
+func (r *Room) recordChat(msg ChatMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ChatLog = append(r.ChatLog, msg)
+	if r.Game != nil {
+		r.Game.replay.recordChat(msg)
+	}
+}
+
+// lobbyRecipients lists connected users who are not playing a match.
+func lobbyRecipients() []string {
+	clientsMu.RLock()
+	var online []string
+	for username := range clients {
+		online = append(online, username)
+	}
+	clientsMu.RUnlock()
+
+	var lobby []string
+	for _, username := range online {
+		if GetRoomIDByUsername(username) == "" {
+			lobby = append(lobby, username)
+		}
+	}
+	return lobby
+}
+
+func deliverChat(recipients []string, msg ChatMessage) {
+	payload := utils.Response{
+		Type:    "chat_message",
+		Success: true,
+		Message: msg.From,
+		Data:    msg,
+	}
+
+	for _, username := range recipients {
+		if isMuted(username, msg.From) {
+			continue
+		}
+		sendToClient(username, payload)
+	}
+}
+
+func isMuted(listener, sender string) bool {
+	chatMu.Lock()
+	defer chatMu.Unlock()
+	return mutedBy[listener][sender]
+}
+
+// takeCooldown reports whether username may act now and, if so, starts a
+// new cooldown window in the given table.
+func takeCooldown(table map[string]time.Time, username string, cooldown time.Duration) bool {
+	chatMu.Lock()
+	defer chatMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(table[username]) < cooldown {
+		return false
+	}
+	table[username] = now
+	return true
+}
+
+func filterProfanity(text string) string {
+	return profanityPattern.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"log"
+	"royaka/internal/utils"
 	"sync"
 	"time"
 
@@ -15,18 +16,117 @@ type ClientConnection struct {
     Username  string
 }
 
-func (c *ClientConnection) SafeWrite(data interface{}) error {
+// SafeWrite serializes writes to the connection and returns the number of
+// bytes sent.
+func (c *ClientConnection) SafeWrite(data interface{}) (int, error) {
     c.Mu.Lock()
     defer c.Mu.Unlock()
 
     if c.Conn == nil {
         log.Println("[WS] No connection to write to")
-        return nil
+        return 0, nil
     }
 
     c.Conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
     defer c.Conn.SetWriteDeadline(time.Time{})
 
-    return c.Conn.WriteJSON(data)
+    return utils.WriteMessageSize(c.Conn, data)
 }
 
+
+// TrackClient binds an authenticated connection to its username so messages
+// can be pushed to the user outside of a match.
+func TrackClient(username string, conn *websocket.Conn) *ClientConnection {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	existing, ok := clients[username]
+	if ok && existing.Conn == conn {
+		return existing
+	}
+	client := &ClientConnection{Conn: conn, Username: username}
+	clients[username] = client
+
+	if !ok {
+		go notifyPresence(username)
+	}
+	return client
+}
+
+// UntrackClient drops every username bound to the connection.
+func UntrackClient(conn *websocket.Conn) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	for username, client := range clients {
+		if client.Conn == conn {
+			delete(clients, username)
+			go notifyPresence(username)
+		}
+	}
+}
+
+// ClientUsername returns the username bound to the connection, if any.
+func ClientUsername(conn *websocket.Conn) string {
+	clientsMu.RLock()
+	defer clientsMu.RUnlock()
+
+	for username, client := range clients {
+		if client.Conn == conn {
+			return username
+		}
+	}
+	return ""
+}
+
+// trackedClient returns the client bound to the connection at login and
+// its username, or nil if no user logged in on it.
+func trackedClient(conn *websocket.Conn) (*ClientConnection, string) {
+	clientsMu.RLock()
+	defer clientsMu.RUnlock()
+
+	for username, client := range clients {
+		if client.Conn == conn {
+			return client, username
+		}
+	}
+	return nil, ""
+}
+
+// RenameClient moves a tracked connection to the user's new name and tells
+// their online friends.
+func RenameClient(oldName, newName string, friends []string) {
+	clientsMu.Lock()
+	if client, ok := clients[oldName]; ok {
+		delete(clients, oldName)
+		client.Username = newName
+		clients[newName] = client
+	}
+	clientsMu.Unlock()
+
+	for _, friend := range friends {
+		if isConnected(friend) {
+			sendToClient(friend, utils.Response{
+				Type:    "friend_renamed",
+				Success: true,
+				Message: oldName + " is now called " + newName,
+				Data:    map[string]string{"old_username": oldName, "username": newName},
+			})
+		}
+	}
+}
+
+// AccountDeleted tells the deleted user's online friends that the
+// friendship is gone.
+func AccountDeleted(username string, friends []string) {
+	for _, friend := range friends {
+		if isConnected(friend) {
+			sendToClient(friend, utils.Response{
+				Type:    "friend_removed",
+				Success: true,
+				Message: username + " deleted their account",
+				Data:    map[string]string{"username": username},
+			})
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package game
+
+import (
+	"royaka/internal/config"
+	"royaka/internal/model"
+	"time"
+)
+
+// Engine starts matches with the settings from the server config: the
+// tick rate of enhanced matches and the rules of each mode. The handlers
+// that create matches are its methods.
+type Engine struct {
+	tickRate time.Duration
+	rules    map[string]model.MatchRules
+}
+
+func NewEngine(cfg config.Config) *Engine {
+	rules := cfg.Rules
+	if rules == nil {
+		rules, _ = model.ParseMatchRules(nil)
+	}
+	return &Engine{
+		tickRate: time.Duration(cfg.TickRate),
+		rules:    rules,
+	}
+}
+
+// RulesFor returns the rules for a game mode.
+func (e *Engine) RulesFor(mode string) model.MatchRules {
+	return e.rules[mode]
+}
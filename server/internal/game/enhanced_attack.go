@@ -1,7 +1,6 @@
 package game
 
 import (
-	"fmt"
 	"math"
 	"royaka/internal/model"
 	"time"
@@ -494,8 +493,8 @@ func (g *Game) attackTroop(attacker *model.TroopInstance, target *model.TroopIns
 	target.Template.HP -= damage
 	attacker.LastAttackTime = currentTime
 
-	fmt.Printf("Troop %s attacks troop %s for %.1f damage. Target HP: %.1f\n",
-		attacker.Template.Name, target.Template.Name, damage, target.Template.HP)
+	g.combatLog("Troop attacks troop", "owner", attacker.Owner, "troop", attacker.Template.Name,
+		"target", target.Template.Name, "damage", damage, "target_hp", target.Template.HP)
 
 	// Kiểm tra target có chết không
 	if target.Template.HP <= 0 {
@@ -504,7 +503,7 @@ func (g *Game) attackTroop(attacker *model.TroopInstance, target *model.TroopIns
 		// Thêm reward cho việc giết troop
 		g.addKillReward(attacker.Owner, target)
 
-		fmt.Printf("Troop %s killed!\n", target.Template.Name)
+		g.combatLog("Troop killed", "owner", target.Owner, "troop", target.Template.Name, "by", attacker.Template.Name)
 	}
 }
 
@@ -541,13 +540,14 @@ func (g *Game) attackTower(troop *model.TroopInstance) {
 	closestTower.Template.HP -= damage
 	troop.LastAttackTime = currentTime
 
-	fmt.Printf("Troop %s attacks tower %s for %.1f damage. Tower HP: %.1f\n",
-		troop.Template.Name, closestTower.Template.Type, damage, closestTower.Template.HP)
+	g.combatLog("Troop attacks tower", "owner", troop.Owner, "troop", troop.Template.Name,
+		"tower", closestTower.Template.Type, "damage", damage, "tower_hp", closestTower.Template.HP)
 
 	if closestTower.Template.HP <= 0 {
 		closestTower.IsDestroyed = true
 
-		fmt.Printf("Tower %s destroyed!\n", closestTower.Template.Type)
+		g.logger().Info("Tower destroyed", "area", "combat", "owner", closestTower.Owner,
+			"tower", closestTower.Template.Type, "by", troop.Template.Name)
 		g.addTowerDestroyReward(troop.Owner, closestTower)
 		g.checkWinCondition()
 	}
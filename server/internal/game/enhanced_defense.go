@@ -1,7 +1,6 @@
 package game
 
 import (
-	"fmt"
 	"math"
 	"royaka/internal/model"
 	"time"
@@ -74,14 +73,14 @@ func (g *Game) towerAttackTroop(tower *model.TowerInstance, target *model.TroopI
 	target.Template.HP -= damage
 	tower.LastAttackTime = currentTime
 
-	fmt.Printf("Tower %s attacks troop %s for %.1f damage. Troop HP: %.1f\n",
-		tower.Template.Type, target.Template.Name, damage, target.Template.HP)
+	g.combatLog("Tower attacks troop", "owner", tower.Owner, "tower", tower.Template.Type,
+		"target", target.Template.Name, "damage", damage, "target_hp", target.Template.HP)
 
 	// Kiểm tra troop có chết không
 	if target.Template.HP <= 0 {
 		target.IsDead = true
 
 		g.addKillReward(tower.Owner, target)
-		fmt.Printf("Troop %s killed by tower!\n", target.Template.Name)
+		g.combatLog("Troop killed", "owner", target.Owner, "troop", target.Template.Name, "by", tower.Template.Type)
 	}
 }
\ No newline at end of file
@@ -363,29 +363,34 @@ func (g *Game) checkWinCondition() {
 			"winner": winner,
 		},
 	}
-	sendToClient(g.Player1.User.Username, gameOverPayload)
-	sendToClient(g.Player2.User.Username, gameOverPayload)
+	g.broadcast(gameOverPayload)
 }
 
 // AddKillReward - Thêm phần thưởng khi giết troop
 func (g *Game) addKillReward(playerName string, killedTroop *model.TroopInstance) {
 	reward := killedTroop.Template.EXP // Thưởng bằng một nửa EXP của troop bị giết
 
+	player := g.Player2
 	if playerName == g.Player1.User.Username {
-		g.Player1.Gold += reward
-	} else {
-		g.Player2.Gold += reward
+		player = g.Player1
 	}
+	player.Gold += reward
+	player.Stats.TroopsKilled++
 }
 
 // AddTowerDestroyReward - Thêm phần thưởng khi phá tower
 func (g *Game) addTowerDestroyReward(playerName string, killedTower *model.TowerInstance) {
 	reward := killedTower.Template.EXP
 
+	player := g.Player2
 	if playerName == g.Player1.User.Username {
-		g.Player1.Gold += reward
+		player = g.Player1
+	}
+	player.Gold += reward
+	if killedTower.Template.Type == "king" {
+		player.Stats.KingTowers++
 	} else {
-		g.Player2.Gold += reward
+		player.Stats.GuardTowers++
 	}
 }
 
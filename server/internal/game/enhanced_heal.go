@@ -1,7 +1,6 @@
 package game
 
 import (
-	"fmt"
 	"math"
 	"royaka/internal/model"
 	"royaka/internal/utils"
@@ -311,8 +310,8 @@ func (g *Game) moveHealerBackToSafety(healer *model.TroopInstance, speed float64
 		g.handleHealerOutsideRiver(healer, currentX, speed, directionY)
 	}
 
-	fmt.Printf("Healer %s retreating to safety at (%.1f, %.1f)\n",
-		healer.Template.Name, healer.Position.X, healer.Position.Y)
+	g.combatLog("Healer retreating", "owner", healer.Owner, "troop", healer.Template.Name,
+		"x", healer.Position.X, "y", healer.Position.Y)
 }
 
 func (g *Game) handleHealerInRiver(healer *model.TroopInstance, currentX, speed, directionY float64) {
@@ -402,12 +401,8 @@ func (g *Game) healAlly(healer *model.TroopInstance, target *model.TroopInstance
 
 	healer.LastAttackTime = currentTime
 
-	fmt.Printf("Healer %s healed ally %s for %.2f HP (%.1f/%.1f)\n",
-		healer.Template.Name,
-		target.Template.Name,
-		healAmount,
-		target.Template.HP,
-		target.Template.MaxHP)
+	g.combatLog("Healer heals ally", "owner", healer.Owner, "troop", healer.Template.Name,
+		"target", target.Template.Name, "heal", healAmount, "target_hp", target.Template.HP)
 }
 
 
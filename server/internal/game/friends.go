@@ -0,0 +1,524 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const challengeTimeout = 30 * time.Second
+
+const (
+	PresenceOffline = "offline"
+	PresenceOnline  = "online"
+	PresenceInQueue = "in_queue"
+	PresenceInMatch = "in_match"
+)
+
+var (
+	errNotFriends       = errors.New("you are not friends")
+	errAlreadyFriends   = errors.New("already friends")
+	errAlreadyRequested = errors.New("friend request already sent")
+	errNoRequest        = errors.New("no friend request from this user")
+)
+
+type challenge struct {
+	Challenger string
+	Target     string
+	Mode       string
+	Rules      model.MatchRules
+	CreatedAt  time.Time
+}
+
+var (
+	// target -> challenger -> challenge
+	challenges   = make(map[string]map[string]*challenge)
+	challengesMu sync.Mutex
+)
+
+// ==== FRIEND LIST ====
+
+func HandleSendFriendRequest(conn *websocket.Conn, data json.RawMessage) {
+	username, req, ok := parseFriendRequest(conn, data, "friend_request_response")
+	if !ok {
+		return
+	}
+
+	accepted := false
+	err := model.ModifyUsers(func(byName map[string]*model.User) error {
+		me, target := byName[username], byName[req.Target]
+		if me == nil || target == nil {
+			return model.ErrUserNotFound
+		}
+		if slices.Contains(me.Friends, req.Target) {
+			return errAlreadyFriends
+		}
+		// A request in the other direction means both want to be friends
+		if slices.Contains(me.FriendRequests, req.Target) {
+			makeFriends(me, target)
+			accepted = true
+			return nil
+		}
+		if slices.Contains(target.FriendRequests, username) {
+			return errAlreadyRequested
+		}
+		target.FriendRequests = append(target.FriendRequests, username)
+		return nil
+	})
+	if err != nil {
+		writeFriendError(conn, "friend_request_response", err)
+		return
+	}
+
+	if accepted {
+		log.Printf("[INFO][FRIEND] %s and %s are now friends", username, req.Target)
+		notifyFriendshipStarted(username, req.Target)
+	} else {
+		log.Printf("[INFO][FRIEND] %s sent a friend request to %s", username, req.Target)
+		sendToClient(req.Target, utils.Response{
+			Type:    "friend_request_received",
+			Success: true,
+			Message: username + " wants to be your friend",
+			Data:    map[string]string{"from": username},
+		})
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "friend_request_response",
+		Success: true,
+		Message: "Friend request sent",
+		Data:    map[string]interface{}{"target": req.Target, "accepted": accepted},
+	})
+}
+
+func HandleRespondFriendRequest(conn *websocket.Conn, data json.RawMessage) {
+	username, req, ok := parseFriendRequest(conn, data, "friend_respond_response")
+	if !ok {
+		return
+	}
+
+	err := model.ModifyUsers(func(byName map[string]*model.User) error {
+		me, from := byName[username], byName[req.Target]
+		if me == nil || from == nil {
+			return model.ErrUserNotFound
+		}
+		if !slices.Contains(me.FriendRequests, req.Target) {
+			return errNoRequest
+		}
+		if req.Accept {
+			makeFriends(me, from)
+		} else {
+			me.FriendRequests = removeName(me.FriendRequests, req.Target)
+		}
+		return nil
+	})
+	if err != nil {
+		writeFriendError(conn, "friend_respond_response", err)
+		return
+	}
+
+	message := "Friend request declined"
+	if req.Accept {
+		message = "Friend request accepted"
+		log.Printf("[INFO][FRIEND] %s and %s are now friends", username, req.Target)
+		notifyFriendshipStarted(username, req.Target)
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "friend_respond_response",
+		Success: true,
+		Message: message,
+		Data:    map[string]interface{}{"target": req.Target, "accepted": req.Accept},
+	})
+}
+
+func HandleRemoveFriend(conn *websocket.Conn, data json.RawMessage) {
+	username, req, ok := parseFriendRequest(conn, data, "remove_friend_response")
+	if !ok {
+		return
+	}
+
+	err := model.ModifyUsers(func(byName map[string]*model.User) error {
+		me := byName[username]
+		if me == nil {
+			return model.ErrUserNotFound
+		}
+		if !slices.Contains(me.Friends, req.Target) {
+			return errNotFriends
+		}
+		me.Friends = removeName(me.Friends, req.Target)
+		if other := byName[req.Target]; other != nil {
+			other.Friends = removeName(other.Friends, username)
+		}
+		return nil
+	})
+	if err != nil {
+		writeFriendError(conn, "remove_friend_response", err)
+		return
+	}
+
+	log.Printf("[INFO][FRIEND] %s removed %s", username, req.Target)
+	sendToClient(req.Target, utils.Response{
+		Type:    "friend_removed",
+		Success: true,
+		Message: username + " removed you from friends",
+		Data:    map[string]string{"username": username},
+	})
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "remove_friend_response",
+		Success: true,
+		Message: "Friend removed",
+		Data:    map[string]string{"target": req.Target},
+	})
+}
+
+func HandleGetFriends(conn *websocket.Conn, data json.RawMessage) {
+	username := ClientUsername(conn)
+	user, ok := model.FindUserByUsername(username)
+	if username == "" || !ok {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "friends_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	friends := make([]map[string]string, 0, len(user.Friends))
+	for _, name := range user.Friends {
+		friends = append(friends, map[string]string{
+			"username": name,
+			"presence": Presence(name),
+		})
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "friends_response",
+		Success: true,
+		Message: "Friends loaded",
+		Data: map[string]interface{}{
+			"friends":  friends,
+			"requests": user.FriendRequests,
+		},
+	})
+}
+
+// ==== CHALLENGES ====
+
+func (e *Engine) HandleChallengeFriend(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChallengeRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || req.Target == "" || username == "" || req.Target == username {
+		writeFriendError(conn, "challenge_response", errors.New(invalidRequestMessage))
+		return
+	}
+	if _, ok := matchQueues[req.Mode]; !ok {
+		writeFriendError(conn, "challenge_response", errors.New("invalid game mode"))
+		return
+	}
+	if Draining() {
+		writeFriendError(conn, "challenge_response", errors.New(shutdownMessage))
+		return
+	}
+	// Private matches may tweak the mode's rules
+	rules, err := e.RulesFor(req.Mode).WithOverrides(req.Rules)
+	if err != nil {
+		writeFriendError(conn, "challenge_response", err)
+		return
+	}
+
+	user, ok := model.FindUserByUsername(username)
+	if !ok || !slices.Contains(user.Friends, req.Target) {
+		writeFriendError(conn, "challenge_response", errNotFriends)
+		return
+	}
+	if user.PlayRestriction() != nil {
+		writeFriendError(conn, "challenge_response", errors.New(RestrictionMessage(user.Ban)))
+		return
+	}
+	if Presence(username) != PresenceOnline {
+		writeFriendError(conn, "challenge_response", errors.New("you are busy"))
+		return
+	}
+	if Presence(req.Target) != PresenceOnline {
+		writeFriendError(conn, "challenge_response", errors.New("player "+req.Target+" is not available"))
+		return
+	}
+
+	c := &challenge{Challenger: username, Target: req.Target, Mode: req.Mode, Rules: rules, CreatedAt: time.Now()}
+	challengesMu.Lock()
+	if challenges[req.Target] == nil {
+		challenges[req.Target] = make(map[string]*challenge)
+	}
+	challenges[req.Target][username] = c
+	challengesMu.Unlock()
+
+	time.AfterFunc(challengeTimeout, func() {
+		if takeChallenge(c.Target, c.Challenger) == c {
+			sendToClient(c.Challenger, utils.Response{
+				Type:    "challenge_expired",
+				Success: false,
+				Message: c.Target + " did not answer your challenge",
+				Data:    map[string]string{"target": c.Target},
+			})
+		}
+	})
+
+	log.Printf("[INFO][CHALLENGE] %s challenged %s (%s)", username, req.Target, req.Mode)
+	sendToClient(req.Target, utils.Response{
+		Type:    "challenge_received",
+		Success: true,
+		Message: username + " challenged you",
+		Data: map[string]interface{}{
+			"from":    username,
+			"mode":    req.Mode,
+			"rules":   rules,
+			"expires": c.CreatedAt.Add(challengeTimeout),
+		},
+	})
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "challenge_response",
+		Success: true,
+		Message: "Challenge sent",
+		Data:    map[string]string{"target": req.Target, "mode": req.Mode},
+	})
+}
+
+func (e *Engine) HandleRespondChallenge(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChallengeResponseRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || req.Challenger == "" || username == "" {
+		writeFriendError(conn, "challenge_respond_response", errors.New(invalidRequestMessage))
+		return
+	}
+
+	c := takeChallenge(username, req.Challenger)
+	if c == nil {
+		writeFriendError(conn, "challenge_respond_response", errors.New("challenge not found or expired"))
+		return
+	}
+
+	if !req.Accept {
+		sendToClient(c.Challenger, utils.Response{
+			Type:    "challenge_declined",
+			Success: false,
+			Message: username + " declined your challenge",
+			Data:    map[string]string{"target": username},
+		})
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "challenge_respond_response",
+			Success: true,
+			Message: "Challenge declined",
+		})
+		return
+	}
+
+	if err := e.startDirectMatch(c.Challenger, c.Target, c.Mode, c.Rules); err != nil {
+		log.Printf("[WARN][CHALLENGE] %s vs %s failed: %v", c.Challenger, c.Target, err)
+		sendToClient(c.Challenger, utils.Response{
+			Type:    "challenge_declined",
+			Success: false,
+			Message: err.Error(),
+			Data:    map[string]string{"target": username},
+		})
+		writeFriendError(conn, "challenge_respond_response", err)
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "challenge_respond_response",
+		Success: true,
+		Message: "Challenge accepted",
+	})
+}
+
+// startDirectMatch puts two online users in a room without matchmaking.
+func (e *Engine) startDirectMatch(challenger, target, mode string, rules model.MatchRules) error {
+	if Draining() {
+		return errors.New(shutdownMessage)
+	}
+	if Presence(challenger) != PresenceOnline || Presence(target) != PresenceOnline {
+		return errors.New("player is no longer available")
+	}
+
+	clientsMu.RLock()
+	c1, c2 := clients[challenger], clients[target]
+	clientsMu.RUnlock()
+	if c1 == nil || c2 == nil {
+		return errors.New("player is no longer available")
+	}
+
+	u1, ok1 := model.FindUserByUsername(challenger)
+	u2, ok2 := model.FindUserByUsername(target)
+	if !ok1 || !ok2 {
+		return model.ErrUserNotFound
+	}
+	if u1.PlayRestriction() != nil || u2.PlayRestriction() != nil {
+		return errors.New("player is not allowed to play")
+	}
+
+	// Challenges always use each player's active deck for the mode
+	deck1, _ := playerDeck(&u1, mode, "")
+	deck2, _ := playerDeck(&u2, mode, "")
+	p1 := model.NewPlayer(&u1, mode, deck1)
+	p2 := model.NewPlayer(&u2, mode, deck2)
+	if p1 == nil || p2 == nil {
+		return errors.New("invalid game mode")
+	}
+
+	RemoveSpectator(c1.Conn)
+	RemoveSpectator(c2.Conn)
+	model.RegisterConnection(c1.Conn, p1)
+	model.RegisterConnection(c2.Conn, p2)
+
+	log.Printf("[INFO][CHALLENGE] starting %s match %s vs %s", mode, challenger, target)
+	e.handleMatch(p1, p2, mode, rules)
+	return nil
+}
+
+func takeChallenge(target, challenger string) *challenge {
+	challengesMu.Lock()
+	defer challengesMu.Unlock()
+
+	c := challenges[target][challenger]
+	if c != nil {
+		delete(challenges[target], challenger)
+		if len(challenges[target]) == 0 {
+			delete(challenges, target)
+		}
+	}
+	return c
+}
+
+// ==== PRESENCE ====
+
+// Presence derives a user's status from their connection, queue and room.
+func Presence(username string) string {
+	clientsMu.RLock()
+	_, online := clients[username]
+	clientsMu.RUnlock()
+	if !online {
+		return PresenceOffline
+	}
+
+	if activeRoomID(username) != "" {
+		return PresenceInMatch
+	}
+
+	pendingMu.RLock()
+	_, queued := pendingPlayers[username]
+	pendingMu.RUnlock()
+	if queued {
+		return PresenceInQueue
+	}
+	return PresenceOnline
+}
+
+// activeRoomID returns the room of a match the user is still playing.
+func activeRoomID(username string) string {
+	roomsMu.RLock()
+	defer roomsMu.RUnlock()
+	for id, room := range rooms {
+		if room.Game == nil || room.Game.WinnerDeclared {
+			continue
+		}
+		if room.Player1.User.Username == username || room.Player2.User.Username == username {
+			return id
+		}
+	}
+	return ""
+}
+
+// notifyPresence pushes the user's current presence to their online friends.
+func notifyPresence(username string) {
+	user, ok := model.FindUserByUsername(username)
+	if !ok || len(user.Friends) == 0 {
+		return
+	}
+
+	payload := utils.Response{
+		Type:    "presence_update",
+		Success: true,
+		Message: "Friend presence changed",
+		Data: map[string]string{
+			"username": username,
+			"presence": Presence(username),
+		},
+	}
+
+	for _, friend := range user.Friends {
+		clientsMu.RLock()
+		_, online := clients[friend]
+		clientsMu.RUnlock()
+		if online {
+			sendToClient(friend, payload)
+		}
+	}
+}
+
+// ==== HELPERS ====
+
+func parseFriendRequest(conn *websocket.Conn, data json.RawMessage, responseType string) (string, utils.FriendRequest, bool) {
+	var req utils.FriendRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || req.Target == "" || username == "" || req.Target == username {
+		log.Printf("[WARN][FRIEND] invalid %s request from %q: %v", responseType, username, err)
+		writeFriendError(conn, responseType, errors.New(invalidRequestMessage))
+		return "", req, false
+	}
+	return username, req, true
+}
+
+func writeFriendError(conn *websocket.Conn, responseType string, err error) {
+	message := err.Error()
+	if message != "" {
+		message = strings.ToUpper(message[:1]) + message[1:]
+	}
+	utils.WriteMessage(conn, utils.Response{
+		Type:    responseType,
+		Success: false,
+		Message: message,
+	})
+}
+
+func makeFriends(a, b *model.User) {
+	a.FriendRequests = removeName(a.FriendRequests, b.Username)
+	b.FriendRequests = removeName(b.FriendRequests, a.Username)
+	if !slices.Contains(a.Friends, b.Username) {
+		a.Friends = append(a.Friends, b.Username)
+	}
+	if !slices.Contains(b.Friends, a.Username) {
+		b.Friends = append(b.Friends, a.Username)
+	}
+}
+
+func notifyFriendshipStarted(a, b string) {
+	for _, pair := range [][2]string{{a, b}, {b, a}} {
+		sendToClient(pair[0], utils.Response{
+			Type:    "friend_added",
+			Success: true,
+			Message: pair[1] + " is now your friend",
+			Data: map[string]string{
+				"username": pair[1],
+				"presence": Presence(pair[1]),
+			},
+		})
+	}
+}
+
+func removeName(names []string, name string) []string {
+	return slices.DeleteFunc(names, func(n string) bool { return n == name })
+}
@@ -11,6 +11,7 @@ import (
 )
 
 type Game struct {
+	RoomID          string
 	Player1         *model.Player
 	Player2         *model.Player
 	Turn            string
@@ -22,12 +23,29 @@ type Game struct {
 	TickerStopChan  chan struct{}
 	BattleSystem    *BattleSystem
 	WinnerDeclared  bool
+	EndReason       string
+	Phase           string
+	PhaseStarted    time.Time
+	Rules           model.MatchRules
 	TurnTimerCancel func()
+	TurnNumber      int  // simple mode turn being played, from 1
+	Paused          bool // saved or restored, waiting for both players
+	replay          *replayRecorder
+
+	pausedAt        time.Time
+	reattached      map[string]bool
+	snapshotMu      sync.Mutex
+	snapshotDropped bool
+	turnMu          sync.Mutex // serializes turns and their timer
+	loopMu          sync.Mutex // held by the enhanced game loop while it updates the match
+	stopLoop        sync.Once
+	resolving       bool
+	turnDeadline    time.Time
 }
 
 // ===================== Game Initialization =====================
 
-func NewGame(p1, p2 *model.Player, mode string) *Game {
+func NewGame(p1, p2 *model.Player, mode string, rules model.MatchRules, tickRate time.Duration) *Game {
 	if mode != "simple" && mode != "enhanced" {
 		log.Fatal("Invalid game mode")
 	}
@@ -37,6 +55,8 @@ func NewGame(p1, p2 *model.Player, mode string) *Game {
 		startingPlayer = p2.User.Username
 	}
 
+	p1.Mana = rules.StartingMana
+	p2.Mana = rules.StartingMana
 	p1.LastManaRegen = time.Now()
 	p2.LastManaRegen = time.Now()
 
@@ -57,7 +77,7 @@ func NewGame(p1, p2 *model.Player, mode string) *Game {
 		BattleMap:      initialEntities,
 		MapMutex:       sync.RWMutex{},
 		TickerStopChan: make(chan struct{}),
-		TickRate:       100 * time.Millisecond,
+		TickRate:       tickRate,
 	}
 
 	game := &Game{
@@ -65,20 +85,25 @@ func NewGame(p1, p2 *model.Player, mode string) *Game {
 		Player2:        p2,
 		Turn:           startingPlayer,
 		Started:        true,
+		StartTime:      time.Now(),
 		Enhanced:       (mode == "enhanced"),
 		BattleSystem:   battleSystem,
 		TickerStopChan: battleSystem.TickerStopChan,
 		WinnerDeclared: false,
+		Phase:          PhaseRegular,
+		Rules:          rules,
+		replay:         newReplayRecorder(),
 	}
 
 	if game.Enhanced {
 		game.StartTime = time.Now()
-		game.MaxTime = 3 * time.Minute
-		time.AfterFunc(3*time.Second, func() {
+		game.MaxTime = time.Duration(rules.MatchDuration)
+		time.AfterFunc(time.Duration(rules.StartDelay), func() {
 			game.StartTime = time.Now()
 			go game.startTicker()
 		})
 	} else if !game.Enhanced {
+		game.TurnNumber = 1
 		game.StartTurnTimer()
 	}
 
@@ -87,6 +112,13 @@ func NewGame(p1, p2 *model.Player, mode string) *Game {
 
 // ===================== Turn Management =====================
 
+func (g *Game) Mode() string {
+	if g.Enhanced {
+		return "enhanced"
+	}
+	return "simple"
+}
+
 func (g *Game) CurrentPlayer() *model.Player {
 	if g.Enhanced {
 		return nil
@@ -97,63 +129,6 @@ func (g *Game) CurrentPlayer() *model.Player {
 	return g.Player2
 }
 
-func (g *Game) SwitchTurn() {
-	if g.Turn == g.Player1.User.Username {
-		g.Turn = g.Player2.User.Username
-	} else {
-		g.Turn = g.Player1.User.Username
-	}
-
-	g.LastTick = time.Now()
-
-	// khởi động timer cho lượt mới
-	g.StartTurnTimer()
-
-	nextPlayer := g.CurrentPlayer()
-	if nextPlayer.Turn > 0 {
-		nextPlayer.Mana += 3
-		if nextPlayer.Mana > 10 {
-			nextPlayer.Mana = 10
-		}
-	}
-}
-
-func (g *Game) SkipTurn(player *model.Player) {
-	player.Turn++
-	g.SwitchTurn()
-}
-
-func (g *Game) StartTurnTimer() {
-	// Hủy timer cũ nếu còn
-	if g.TurnTimerCancel != nil {
-		g.TurnTimerCancel()
-	}
-
-	timer := time.NewTimer(30 * time.Second)
-	cancelChan := make(chan struct{})
-
-	g.TurnTimerCancel = func() {
-		timer.Stop()
-		close(cancelChan)
-	}
-
-	go func(turn string) {
-
-		time.Sleep(1 * time.Second)
-		
-		select {
-		case <-timer.C:
-			// Chỉ xử lý timeout nếu vẫn là lượt đó
-			if g.Turn == turn {
-				log.Printf("[TURN] player %s timed out", turn)
-				g.HandleTurnTimeout()
-			}
-		case <-cancelChan:
-			// Lượt kết thúc hợp lệ
-		}
-	}(g.Turn)
-}
-
 // ===================== Game Tick & Loop =====================
 
 func (g *Game) startTicker() {
@@ -162,6 +137,7 @@ func (g *Game) startTicker() {
 	cleanupTicker := time.NewTicker(5 * time.Second)
 
 	defer func() {
+		manaTicker.Stop()
 		tickTicker.Stop()
 		cleanupTicker.Stop()
 	}()
@@ -169,30 +145,44 @@ func (g *Game) startTicker() {
 	for {
 		select {
 		case <-tickTicker.C:
+			started := time.Now()
+			g.loopMu.Lock()
 			g.UpdateBattleMap()
+			g.loopMu.Unlock()
+			tickDuration.Observe(time.Since(started).Seconds())
 			g.BroadcastGameState()
 		case <-manaTicker.C:
+			g.loopMu.Lock()
 			g.UpdateMana()
+			g.loopMu.Unlock()
 		case <-cleanupTicker.C:
+			g.loopMu.Lock()
 			g.BattleSystem.CleanupDeadEntities()
+			g.loopMu.Unlock()
 		case <-g.BattleSystem.TickerStopChan:
 			return
 		}
 	}
 }
 
+// StopGameLoop stops the game loop. Every way a match ends calls it, so
+// only the first call does anything.
 func (g *Game) StopGameLoop() {
-	if g.Started {
+	g.stopLoop.Do(func() {
 		g.Started = false
 		close(g.TickerStopChan)
-	}
+
+		// The match is over, players are back to being online
+		go notifyPresence(g.Player1.User.Username)
+		go notifyPresence(g.Player2.User.Username)
+	})
 }
 
 func (g *Game) UpdateMana() {
 	now := time.Now()
 
 	for _, player := range []*model.Player{g.Player1, g.Player2} {
-		if player.Mana < 10 && now.Sub(player.LastManaRegen) >= 2*time.Second {
+		if player.Mana < g.Rules.MaxMana && now.Sub(player.LastManaRegen) >= g.manaRegenInterval() {
 			player.Mana++
 			player.LastManaRegen = now
 
@@ -218,121 +208,154 @@ func (g *Game) CheckWinner() (*model.Player, string) {
 	// Kiểm tra King Tower bị phá
 	if g.Player1.Towers["king"].HP <= 0.0 {
 		g.WinnerDeclared = true
+		g.EndReason = model.EndKingDestroyed
 		g.StopGameLoop()
 
-		if g.Enhanced {
-			g.Player1.User.Gold += g.Player1.Gold
-			g.Player2.User.Gold += g.Player2.Gold
-		}
-
-		AwardEXP(g.Player2.User, g.Player1.User, false)
-		fmt.Printf("Winner: %s\n", g.Player2.User.Username)
+		g.AwardEXP(g.Player2, g.Player1, false)
+		g.logger().Info("Match won", "area", "match", "winner", g.Player2.User.Username, "reason", g.EndReason)
 		return g.Player2, g.Player2.User.Username + " wins!"
 	}
 
 	if g.Player2.Towers["king"].HP <= 0.0 {
 		g.WinnerDeclared = true
+		g.EndReason = model.EndKingDestroyed
 		g.StopGameLoop()
 
-		if g.Enhanced {
-			g.Player1.User.Gold += g.Player1.Gold
-			g.Player2.User.Gold += g.Player2.Gold
-		}
-
-		AwardEXP(g.Player1.User, g.Player2.User, false)
-		fmt.Printf("Winner: %s\n", g.Player1.User.Username)
+		g.AwardEXP(g.Player1, g.Player2, false)
+		g.logger().Info("Match won", "area", "match", "winner", g.Player1.User.Username, "reason", g.EndReason)
 		return g.Player1, g.Player1.User.Username + " wins!"
 	}
 
+	// Sudden death: the first tower destroyed decides the match
+	if g.Enhanced && g.Phase == PhaseSuddenDeath && g.Player1.DestroyedCount() != g.Player2.DestroyedCount() {
+		if g.Player1.DestroyedCount() < g.Player2.DestroyedCount() {
+			return g.finish(g.Player1, g.Player2, model.EndSuddenDeath, " wins in sudden death!")
+		}
+		return g.finish(g.Player2, g.Player1, model.EndSuddenDeath, " wins in sudden death!")
+	}
+
 	// Hết giờ trong enhanced mode => xử lý tính điểm
-	if g.Enhanced && time.Since(g.StartTime) > g.MaxTime {
+	if g.Enhanced && !time.Now().Before(g.PhaseEndsAt()) {
 		p1Score := g.Player1.DestroyedCount()
 		p2Score := g.Player2.DestroyedCount()
 
-		g.WinnerDeclared = true
-		g.StopGameLoop()
-
-		// Cộng gold
-		g.Player1.User.Gold += g.Player1.Gold
-		g.Player2.User.Gold += g.Player2.Gold
-
 		if p1Score < p2Score {
-			AwardEXP(g.Player1.User, g.Player2.User, false)
-			fmt.Printf("Winner by score: %s\n", g.Player1.User.Username)
-			return g.Player1, g.Player1.User.Username + " wins by score!"
+			return g.finish(g.Player1, g.Player2, model.EndScore, " wins by score!")
 		}
 
 		if p2Score < p1Score {
-			AwardEXP(g.Player2.User, g.Player1.User, false)
-			fmt.Printf("Winner by score: %s\n", g.Player2.User.Username)
-			return g.Player2, g.Player2.User.Username + " wins by score!"
+			return g.finish(g.Player2, g.Player1, model.EndScore, " wins by score!")
 		}
 
-		// Hòa điểm
-		AwardEXP(g.Player1.User, g.Player2.User, true)
-		fmt.Println("Game ended in a draw by score")
-		return nil, "It's a draw!"
+		// Hòa điểm => overtime, rồi sudden death, cuối cùng so máu trụ
+		if g.advancePhase() {
+			return nil, ""
+		}
+		return g.tiebreak()
 	}
 
 	// Nếu chưa có ai thắng
 	return nil, ""
 }
 
-func (g *Game) SetWinner(winner *model.Player) {
+// SetWinner ends the match in favour of winner and reports whether it did;
+// a match that already has a result is left untouched. It must not be
+// called with the turn or loop lock held.
+func (g *Game) SetWinner(winner *model.Player) bool {
+	defer g.lockMatch()()
+	if g.WinnerDeclared {
+		return false
+	}
+
 	if winner == g.Player1 {
 		g.WinnerDeclared = true
+		g.EndReason = model.EndForfeit
 		g.StopGameLoop()
-		AwardEXP(g.Player1.User, g.Player2.User, false)
+		g.AwardEXP(g.Player1, g.Player2, false)
 	} else if winner == g.Player2 {
 		g.WinnerDeclared = true
+		g.EndReason = model.EndForfeit
 		g.StopGameLoop()
-		AwardEXP(g.Player2.User, g.Player1.User, false)
+		g.AwardEXP(g.Player2, g.Player1, false)
 	}
+	return g.WinnerDeclared
 }
 
-func AwardEXP(winner, loser *model.User, isDraw bool) {
+// AwardEXP stores the match result for both players: EXP, games, the gold
+// they earned in the match, trophies, a reward chest for a win and the
+// match history entry with its replay.
+func (g *Game) AwardEXP(winner, loser *model.Player, isDraw bool) {
+	// Forget the snapshot first so a crash while paying out cannot replay
+	// the match and pay it twice
+	g.dropSnapshot()
+
+	w, l := winner.User, loser.User
+
+	winnerEXP, loserEXP := 30, 0
 	if isDraw {
-		winner.AddExp(10)
-		loser.AddExp(10)
-	} else {
-		winner.GamesWon++
-		winner.AddExp(30)
+		winnerEXP, loserEXP = 10, 10
 	}
 
-	winner.GamesPlayed++
-	loser.GamesPlayed++
+	// The in-match copies show the result; the store only gets the deltas
+	w.AddExp(winnerEXP)
+	l.AddExp(loserEXP)
+	if !isDraw {
+		w.GamesWon++
+	}
+	w.GamesPlayed++
+	l.GamesPlayed++
+	w.Gold += winner.Gold
+	l.Gold += loser.Gold
+
+	if err := model.SaveProgress(w.Username, winnerEXP, !isDraw, winner.Gold); err != nil {
+		log.Printf("[ERROR][MATCH] Saving progress of %s failed: %v", w.Username, err)
+	}
+	if err := model.SaveProgress(l.Username, loserEXP, false, loser.Gold); err != nil {
+		log.Printf("[ERROR][MATCH] Saving progress of %s failed: %v", l.Username, err)
+	}
 
-	model.SaveUser(winner)
-	model.SaveUser(loser)
+	trophies := updateTrophies(w.Username, l.Username, isDraw)
+	if !isDraw {
+		awardChest(w.Username)
+	}
+
+	winner.Stats.Won = !isDraw
+	winner.Stats.Draw = isDraw
+	loser.Stats.Draw = isDraw
+	sendProgress(winner)
+	sendProgress(loser)
+
+	g.recordMatch(winner, loser, isDraw, trophies)
 }
 
 // ===================== Game State Broadcasting =====================
 
 func (g *Game) BroadcastGameState() {
-	elapsed := time.Since(g.StartTime)
-	timeLeft := g.MaxTime - elapsed
+	timeLeft := time.Until(g.PhaseEndsAt())
 	if timeLeft < 0 {
 		timeLeft = 0
 	}
 
-	for _, player := range []*model.Player{g.Player1, g.Player2} {
-		sendToClient(player.User.Username, utils.Response{
-			Type:    "game_state",
-			Success: true,
-			Message: "Game updated",
-			Data: map[string]interface{}{
-				"battleMap":     g.BattleSystem.GetEntityList(),
-				"timeLeft":      timeLeft.Milliseconds(),
-				"player1Guard1": g.Player1.Towers["guard1"].HP,
-				"player1Guard2": g.Player1.Towers["guard2"].HP,
-				"player2Guard1": g.Player2.Towers["guard1"].HP,
-				"player2Guard2": g.Player2.Towers["guard2"].HP,
-			},
-		})
-	}
+	g.broadcast(utils.Response{
+		Type:    "game_state",
+		Success: true,
+		Message: "Game updated",
+		Data: map[string]interface{}{
+			"battleMap":     g.BattleSystem.GetEntityList(),
+			"timeLeft":      timeLeft.Milliseconds(),
+			"phase":         g.Phase,
+			"player1Guard1": g.Player1.Towers["guard1"].HP,
+			"player1Guard2": g.Player1.Towers["guard2"].HP,
+			"player2Guard1": g.Player2.Towers["guard1"].HP,
+			"player2Guard2": g.Player2.Towers["guard2"].HP,
+			"spectators":    SpectatorCount(g.RoomID),
+		},
+	})
 
 	if timeLeft == 0 && !g.WinnerDeclared {
+		g.loopMu.Lock()
 		g.checkWinCondition()
+		g.loopMu.Unlock()
 	}
 }
 
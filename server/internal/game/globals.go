@@ -11,7 +11,7 @@ var (
 	clients   = make(map[string]*ClientConnection)
 	clientsMu sync.RWMutex
 
-	pendingPlayers = make(map[string]bool)
+	pendingPlayers = make(map[string]queueEntry) // by username
 	pendingMu      sync.RWMutex
 
 	rooms   = make(map[string]*Room)
@@ -35,12 +35,25 @@ func sendToClient(username string, payload utils.Response) {
 
 	if !exists || client == nil || client.Conn == nil {
 		log.Printf("[WARN][SEND] Client %s not found or connection is nil", username)
+		sendFailures.Inc("not_connected")
 		return
 	}
 
 	go func() {
-		if err := client.SafeWrite(payload); err != nil {
+		n, err := client.SafeWrite(payload)
+		if err != nil {
 			log.Printf("[ERROR][SEND] Failed to send to %s: %v", username, err)
+			sendFailures.Inc("write_error")
+			return
 		}
+		broadcastBytes.Add(float64(n), payload.Type)
 	}()
 }
+
+// broadcast sends a room-wide event to both players and any spectators.
+func (g *Game) broadcast(payload utils.Response) {
+	sendToClient(g.Player1.User.Username, payload)
+	sendToClient(g.Player2.User.Username, payload)
+	sendToSpectators(g.RoomID, payload)
+	g.replay.record(payload)
+}
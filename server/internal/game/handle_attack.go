@@ -10,12 +10,16 @@ import (
 )
 
 func HandleAttack(conn *websocket.Conn, data json.RawMessage) {
+	if rejectSpectator(conn, "attack_response") {
+		return
+	}
+
 	var req utils.AttackRequest
 
 	// Parse & validate request data
-	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Username == "" || req.Troop == "" || req.Target == "" {
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Troop == "" || req.Target == "" {
 		log.Printf("[WARN][ATTACK] invalid request: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "attack_response",
 			Success: false,
 			Message: invalidRequestMessage,
@@ -23,31 +27,37 @@ func HandleAttack(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
+	// The player acting is the user logged in on the connection
+	username := ClientUsername(conn)
+
 	// Fetch the room from memory
 	roomsMu.RLock()
 	room, exists := rooms[req.RoomID]
 	roomsMu.RUnlock()
 	if !exists {
-		log.Printf("[WARN][ATTACK] Room %s not found for user %s", req.RoomID, req.Username)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][ATTACK] Room %s not found for user %s", req.RoomID, username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "attack_response",
 			Success: false,
 			Message: roomRequestMessage,
 		})
 		return
 	}
+	if rejectPaused(conn, room, "attack_response") {
+		return
+	}
 
 	// Identify the attacker
 	var attacker, defender *model.Player
-	if room.Player1.User.Username == req.Username {
+	if room.Player1.User.Username == username {
 		attacker = room.Player1
 		defender = room.Player2
-	} else if room.Player2.User.Username == req.Username {
+	} else if room.Player2.User.Username == username {
 		attacker = room.Player2
 		defender = room.Player1
 	} else {
-		log.Printf("[WARN][ATTACK] User %s not in room %s", req.Username, req.RoomID)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][ATTACK] User %s not in room %s", username, req.RoomID)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "attack_response",
 			Success: false,
 			Message: "You are not part of this match",
@@ -55,73 +65,33 @@ func HandleAttack(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	if room.Game.CurrentPlayer().User.Username != attacker.User.Username {
-		sendToClient(attacker.User.Username, utils.Response{
+	// Process the attack via the turn state machine
+	log.Printf("[INFO][ATTACK] %s attacking with %s targeting %s in room %s", attacker.User.Username, req.Troop, req.Target, req.RoomID)
+	result, err := room.Game.Act(attacker, TurnAction{Kind: ActionAttack, Troop: req.Troop, Target: req.Target})
+	if err != nil {
+		log.Printf("[WARN][ATTACK] %s's attack in room %s refused: %v", username, req.RoomID, err)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "attack_response",
 			Success: false,
-			Message: "It's not your turn!",
+			Message: turnErrorMessage(err),
 		})
 		return
 	}
 
-	// Find the troop being used for attack
-	var troop *model.Troop
-	for i := range attacker.Troops {
-		if attacker.Troops[i].Name == req.Troop {
-			troop = attacker.Troops[i]
-			break
-		}
-	}
-	if troop == nil {
-		log.Printf("[WARN][ATTACK] Troop %s not found for user %s", req.Troop, req.Username)
-		conn.WriteJSON(utils.Response{
-			Type:    "attack_response",
-			Success: false,
-			Message: "Invalid troop used for attack",
-		})
-		return
-	}
-
-	// Process the attack via game logic
-	log.Printf("[INFO][ATTACK] %s attacking with %s targeting %s in room %s", attacker.User.Username, troop.Name, req.Target, req.RoomID)
-	damage, isCrit, message := room.Game.PlayTurnSimple(attacker, troop, req.Target)
-	isDestroyed := defender.Towers[req.Target].HP <= 0
-
-	success := damage > 0 || isDestroyed
-
-	payload := utils.Response{
+	room.Game.broadcast(utils.Response{
 		Type:    "attack_response",
-		Success: success,
-		Message: message,
+		Success: true,
+		Message: result.Message,
 		Data: map[string]interface{}{
 			"attacker":    attacker,
 			"defender":    defender,
-			"troop":       troop.Name,
-			"target":      req.Target,
-			"damage":      int(damage),
-			"isCrit":      isCrit,
-			"isDestroyed": isDestroyed,
+			"troop":       result.Troop,
+			"target":      result.Target,
+			"damage":      result.Amount,
+			"isCrit":      result.Crit,
+			"isDestroyed": result.Destroyed,
 			"turn":        room.Game.Turn,
 		},
-	}
-
-	sendToClient(room.Player1.User.Username, payload)
-	sendToClient(room.Player2.User.Username, payload)
-
-	if defender.Towers["king"].HP <= 0 {
-		winner, result := room.Game.CheckWinner()
-		if result == "" {
-			return
-		}
-		gameOverPayload := utils.Response{
-			Type:    "game_over_response",
-			Success: true,
-			Message: result,
-			Data: map[string]interface{}{
-				"winner": winner,
-			},
-		}
-		sendToClient(room.Player1.User.Username, gameOverPayload)
-		sendToClient(room.Player2.User.Username, gameOverPayload)
-	}
-}
\ No newline at end of file
+	})
+	room.Game.broadcastTurn(result)
+}
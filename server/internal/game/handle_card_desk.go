@@ -13,7 +13,7 @@ func HandleGetDesk(conn *websocket.Conn, data json.RawMessage) {
 	troops, err := model.LoadTroop()
 	if err != nil {
 		log.Println("loadTroop error:", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "deck_response",
 			Success: false,
 			Message: "Failed to load troops",
@@ -21,7 +21,7 @@ func HandleGetDesk(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	conn.WriteJSON(utils.Response{
+	utils.WriteMessage(conn, utils.Response{
 		Type:    "deck_response",
 		Success: true,
 		Message: "Troop data loaded",
@@ -0,0 +1,110 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+func HandleGetCollection(conn *websocket.Conn, data json.RawMessage) {
+	username := ClientUsername(conn)
+	if username == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "collection_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	user, templates, err := model.SyncCollection(username)
+	if err != nil {
+		log.Printf("[ERROR][COLLECTION] Failed to load collection for %s: %v", username, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "collection_response",
+			Success: false,
+			Message: "Failed to load collection",
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "collection_response",
+		Success: true,
+		Message: "Collection loaded",
+		Data: map[string]interface{}{
+			"gold":      user.Gold,
+			"max_level": model.MaxCardLevel,
+			"cards":     user.Collection(templates),
+		},
+	})
+}
+
+func HandleUpgradeCard(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.UpgradeCardRequest
+
+	if err := json.Unmarshal(data, &req); err != nil || req.Card == "" {
+		log.Printf("[WARN][COLLECTION] invalid upgrade request: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "upgrade_card_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	username := ClientUsername(conn)
+	if username == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "upgrade_card_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	user, cost, err := model.UpgradeCard(username, req.Card)
+	if err != nil {
+		log.Printf("[WARN][COLLECTION] %s failed to upgrade %s: %v", username, req.Card, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "upgrade_card_response",
+			Success: false,
+			Message: upgradeErrorMessage(err),
+		})
+		return
+	}
+
+	level := user.CardLevel(req.Card)
+	log.Printf("[INFO][COLLECTION] %s upgraded %s to level %d for %d gold", username, req.Card, level, cost)
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "upgrade_card_response",
+		Success: true,
+		Message: "Card upgraded",
+		Data: map[string]interface{}{
+			"card":  req.Card,
+			"level": level,
+			"cost":  cost,
+			"gold":  user.Gold,
+		},
+	})
+}
+
+func upgradeErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, model.ErrUnknownCard):
+		return "Unknown card"
+	case errors.Is(err, model.ErrCardNotOwned):
+		return "You do not own this card"
+	case errors.Is(err, model.ErrCardMaxLevel):
+		return "Card is already at max level"
+	case errors.Is(err, model.ErrNotEnoughGold):
+		return "Not enough gold"
+	default:
+		return "Failed to upgrade card"
+	}
+}
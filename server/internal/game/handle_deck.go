@@ -0,0 +1,200 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+type deckView struct {
+	model.Deck
+	model.DeckStats
+	Valid bool `json:"valid"`
+}
+
+func HandleGetDecks(conn *websocket.Conn, data json.RawMessage) {
+	username := ClientUsername(conn)
+	if username == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "decks_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	user, templates, err := model.SyncCollection(username)
+	if err != nil {
+		log.Printf("[ERROR][DECK] Failed to load decks for %s: %v", username, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "decks_response",
+			Success: false,
+			Message: "Failed to load decks",
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "decks_response",
+		Success: true,
+		Message: "Decks loaded",
+		Data:    decksPayload(user, templates),
+	})
+}
+
+func HandleSaveDeck(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.DeckRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || username == "" {
+		log.Printf("[WARN][DECK] invalid save request: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "save_deck_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	_, stats, err := model.SaveDeck(username, model.Deck{Name: req.Name, Cards: req.Cards})
+	if err != nil {
+		log.Printf("[WARN][DECK] %s failed to save deck %q: %v", username, req.Name, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "save_deck_response",
+			Success: false,
+			Message: deckErrorMessage(err),
+		})
+		return
+	}
+
+	log.Printf("[INFO][DECK] %s saved deck %q (avg mana %.1f)", username, req.Name, stats.AverageMana)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "save_deck_response",
+		Success: true,
+		Message: "Deck saved",
+		Data: map[string]interface{}{
+			"name":         strings.TrimSpace(req.Name),
+			"cards":        req.Cards,
+			"average_mana": stats.AverageMana,
+			"rarities":     stats.Rarities,
+		},
+	})
+}
+
+func HandleDeleteDeck(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.DeckRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || username == "" || req.Name == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "delete_deck_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	if _, err := model.DeleteDeck(username, req.Name); err != nil {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "delete_deck_response",
+			Success: false,
+			Message: deckErrorMessage(err),
+		})
+		return
+	}
+
+	log.Printf("[INFO][DECK] %s deleted deck %q", username, req.Name)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "delete_deck_response",
+		Success: true,
+		Message: "Deck deleted",
+		Data:    map[string]string{"name": req.Name},
+	})
+}
+
+func HandleSetActiveDeck(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.DeckRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || username == "" || req.Name == "" || req.Mode == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "set_active_deck_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	user, err := model.SetActiveDeck(username, req.Mode, req.Name)
+	if err != nil {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "set_active_deck_response",
+			Success: false,
+			Message: deckErrorMessage(err),
+		})
+		return
+	}
+
+	log.Printf("[INFO][DECK] %s set active %s deck to %q", username, req.Mode, req.Name)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "set_active_deck_response",
+		Success: true,
+		Message: "Active deck updated",
+		Data:    user.ActiveDecks,
+	})
+}
+
+func decksPayload(user model.User, templates []model.Troop) map[string]interface{} {
+	decks := make([]deckView, 0, len(user.Decks))
+	for _, d := range user.Decks {
+		stats, err := user.ValidateDeck(templates, d)
+		decks = append(decks, deckView{Deck: d, DeckStats: stats, Valid: err == nil})
+	}
+
+	active := user.ActiveDecks
+	if active == nil {
+		active = map[string]string{}
+	}
+
+	return map[string]interface{}{
+		"decks":     decks,
+		"active":    active,
+		"deck_size": model.DeckSize,
+		"max_decks": model.MaxDecks,
+	}
+}
+
+// playerDeck resolves the cards a user brings into a match of the given mode.
+func playerDeck(user *model.User, mode, name string) ([]string, error) {
+	templates, err := model.LoadTroop()
+	if err != nil {
+		return nil, err
+	}
+	user.UnlockCards(templates)
+	return user.ResolveDeck(templates, mode, name)
+}
+
+// deckErrorMessage turns deck validation errors into client messages,
+// keeping details such as the offending card name.
+func deckErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, model.ErrDeckNotFound):
+		return "Deck not found"
+	case errors.Is(err, model.ErrDeckName):
+		return "Deck name must be 1-20 characters"
+	case errors.Is(err, model.ErrInvalidDeckMode):
+		return "Invalid game mode"
+	case errors.Is(err, model.ErrUnknownCard), errors.Is(err, model.ErrCardNotOwned),
+		errors.Is(err, model.ErrDeckSize), errors.Is(err, model.ErrDeckDuplicate),
+		errors.Is(err, model.ErrTooManyDecks), errors.Is(err, model.ErrDeckRarity):
+		msg := err.Error()
+		return strings.ToUpper(msg[:1]) + msg[1:]
+	default:
+		return "Failed to update deck"
+	}
+}
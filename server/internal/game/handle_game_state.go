@@ -13,9 +13,9 @@ func HandleGetGame(conn *websocket.Conn, data json.RawMessage) {
 	var req utils.GameRequest
 
 	// Parse & validate request
-	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Username == "" {
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" {
 		log.Printf("[WARN][GAME] invalid request: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "game_response",
 			Success: false,
 			Message: invalidRequestMessage,
@@ -23,13 +23,16 @@ func HandleGetGame(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
+	// Players only see the match as the user logged in on the connection
+	username := ClientUsername(conn)
+
 	// Get room safely
 	roomsMu.RLock()
 	room, exists := rooms[req.RoomID]
 	roomsMu.RUnlock()
 	if !exists {
-		log.Printf("[WARN][GAME] room %s not found for user %s", req.RoomID, req.Username)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][GAME] room %s not found for user %s", req.RoomID, username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "game_response",
 			Success: false,
 			Message: roomRequestMessage,
@@ -39,13 +42,13 @@ func HandleGetGame(conn *websocket.Conn, data json.RawMessage) {
 
 	// Identify current player and opponent
 	var currentUser, opponent *model.Player
-	if room.Player1.User.Username == req.Username {
+	if room.Player1.User.Username == username {
 		currentUser, opponent = room.Player1, room.Player2
-	} else if room.Player2.User.Username == req.Username {
+	} else if room.Player2.User.Username == username {
 		currentUser, opponent = room.Player2, room.Player1
 	} else {
-		log.Printf("[WARN][GAME] user %s not in room %s", req.Username, req.RoomID)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][GAME] user %s not in room %s", username, req.RoomID)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "game_response",
 			Success: false,
 			Message: "Player not in room",
@@ -54,8 +57,9 @@ func HandleGetGame(conn *websocket.Conn, data json.RawMessage) {
 	}
 
 	dataPayload := map[string]interface{}{
-		"user":     currentUser,
-		"opponent": opponent,
+		"user":       currentUser,
+		"opponent":   opponent,
+		"spectators": SpectatorCount(room.ID),
 	}
 
 	if room.Game.Enhanced {
@@ -64,6 +68,9 @@ func HandleGetGame(conn *websocket.Conn, data json.RawMessage) {
 		dataPayload["time"] = room.Game.MaxTime.Milliseconds()
 	} else {
 		dataPayload["turn"] = room.Game.Turn
+		dataPayload["turn_state"] = room.Game.TurnState()
+		dataPayload["turn_number"] = room.Game.TurnNumber
+		dataPayload["legal_actions"] = room.Game.LegalActions(currentUser)
 	}
 
 	payload := utils.Response{
@@ -73,7 +80,7 @@ func HandleGetGame(conn *websocket.Conn, data json.RawMessage) {
 		Data:    dataPayload,
 	}
 
-	conn.WriteJSON(payload)
+	utils.WriteMessage(conn, payload)
 
-	log.Printf("[INFO][GAME] sent game state to %s in room %s", req.Username, req.RoomID)
+	log.Printf("[INFO][GAME] sent game state to %s in room %s", username, req.RoomID)
 }
@@ -10,12 +10,16 @@ import (
 )
 
 func HandleHeal(conn *websocket.Conn, data json.RawMessage) {
+	if rejectSpectator(conn, "heal_response") {
+		return
+	}
+
 	var req utils.HealRequest
 
 	// Parse & validate request data
-	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Username == "" || req.Troop == "" {
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Troop == "" {
 		log.Printf("[WARN][HEAL] invalid request: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "heal_response",
 			Success: false,
 			Message: invalidRequestMessage,
@@ -23,31 +27,37 @@ func HandleHeal(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
+	// The player acting is the user logged in on the connection
+	username := ClientUsername(conn)
+
 	// Fetch the room from memory
 	roomsMu.RLock()
 	room, exists := rooms[req.RoomID]
 	roomsMu.RUnlock()
 	if !exists {
-		log.Printf("[WARN][HEAL] Room %s not found for user %s", req.RoomID, req.Username)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][HEAL] Room %s not found for user %s", req.RoomID, username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "heal_response",
 			Success: false,
 			Message: roomRequestMessage,
 		})
 		return
 	}
+	if rejectPaused(conn, room, "heal_response") {
+		return
+	}
 
 	// Identify the player
 	var player, opponent *model.Player
-	if room.Player1.User.Username == req.Username {
+	if room.Player1.User.Username == username {
 		player = room.Player1
 		opponent = room.Player2
-	} else if room.Player2.User.Username == req.Username {
+	} else if room.Player2.User.Username == username {
 		player = room.Player2
 		opponent = room.Player1
 	} else {
-		log.Printf("[WARN][HEAL] User %s not in room %s", req.Username, req.RoomID)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][HEAL] User %s not in room %s", username, req.RoomID)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "heal_response",
 			Success: false,
 			Message: "You are not part of this match",
@@ -55,51 +65,31 @@ func HandleHeal(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	// Find the troop being used for healing
-	var troop *model.Troop
-	for i := range player.Troops {
-		if player.Troops[i].Name == req.Troop {
-			troop = player.Troops[i]
-			break
-		}
-	}
-	if troop == nil {
-		log.Printf("[WARN][HEAL] Troop %s not found for user %s", req.Troop, req.Username)
-		conn.WriteJSON(utils.Response{
+	// Process the heal via the turn state machine
+	result, err := room.Game.Act(player, TurnAction{Kind: ActionHeal, Troop: req.Troop})
+	if err != nil {
+		log.Printf("[WARN][HEAL] %s's heal in room %s refused: %v", username, req.RoomID, err)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "heal_response",
 			Success: false,
-			Message: "Invalid troop used for healing",
+			Message: turnErrorMessage(err),
 		})
 		return
 	}
 
-	// Call the heal method
-	actualHealed, healedTower, message := room.Game.HealTower(player, troop)
-	if actualHealed == 0 {
-		conn.WriteJSON(utils.Response{
-			Type:    "heal_response",
-			Success: false,
-			Message: message,
-		})
-		return
-	}
-
-	// Prepare response payload
-	payload := utils.Response{
+	// Broadcast to both players
+	room.Game.broadcast(utils.Response{
 		Type:    "heal_response",
 		Success: true,
-		Message: message,
+		Message: result.Message,
 		Data: map[string]interface{}{
 			"player":      player,
 			"opponent":    opponent,
-			"troop":       troop.Name,
-			"healedTower": healedTower,
-			"healAmount":  actualHealed,
+			"troop":       result.Troop,
+			"healedTower": player.Towers[result.Target],
+			"healAmount":  result.Amount,
 			"turn":        room.Game.Turn,
 		},
-	}
-
-	// Broadcast to both players
-	sendToClient(room.Player1.User.Username, payload)
-	sendToClient(room.Player2.User.Username, payload)
-}
\ No newline at end of file
+	})
+	room.Game.broadcastTurn(result)
+}
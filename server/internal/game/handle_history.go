@@ -0,0 +1,140 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+func HandleGetMatchHistory(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.MatchHistoryRequest
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			utils.WriteMessage(conn, utils.Response{
+				Type:    "match_history_response",
+				Success: false,
+				Message: invalidRequestMessage,
+			})
+			return
+		}
+	}
+
+	if req.Username == "" {
+		req.Username = ClientUsername(conn)
+	}
+	if _, ok := model.FindUserByUsername(req.Username); req.Username == "" || !ok {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "match_history_response",
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+
+	payload, err := MatchHistoryPage(req.Username, req.Offset, req.Limit)
+	if err != nil {
+		log.Printf("[ERROR][HISTORY] Failed to load history for %s: %v", req.Username, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "match_history_response",
+			Success: false,
+			Message: "Failed to load match history",
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "match_history_response",
+		Success: true,
+		Message: "Match history loaded",
+		Data:    payload,
+	})
+}
+
+// MatchHistoryPage builds the history payload shared by the websocket and
+// REST APIs: a page of matches, newest first, and the user's win rates.
+func MatchHistoryPage(username string, offset, limit int) (map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+
+	matches, total, err := model.MatchHistory(username, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := model.SummarizeMatches(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"username": username,
+		"matches":  matches,
+		"total":    total,
+		"offset":   offset,
+		"limit":    limit,
+		"summary":  summary,
+	}, nil
+}
+
+// recordMatch stores the finished match in both players' history along with
+// its replay, then tells the players where to find it.
+func (g *Game) recordMatch(winner, loser *model.Player, isDraw bool, trophies map[string]model.TrophyChange) {
+	now := time.Now()
+	record := model.MatchRecord{
+		ID:         model.NewMatchID(),
+		Mode:       g.Mode(),
+		StartedAt:  g.replay.start,
+		EndedAt:    now,
+		DurationMs: now.Sub(g.replay.start).Milliseconds(),
+		EndReason:  g.EndReason,
+	}
+	matchesFinished.Inc(record.Mode, record.EndReason)
+	if !isDraw {
+		record.Winner = winner.User.Username
+	}
+
+	for _, p := range []*model.Player{g.Player1, g.Player2} {
+		record.Players = append(record.Players, model.MatchPlayer{
+			Username:        p.User.Username,
+			Deck:            p.Deck,
+			TowersDestroyed: g.Opponent(p).DestroyedCount(),
+			Gold:            p.Gold,
+			TrophyDelta:     trophies[p.User.Username].Delta,
+			Stats:           p.Stats,
+		})
+	}
+
+	record.HasReplay = true
+	if err := model.SaveReplay(g.replay.build(record)); err != nil {
+		log.Printf("[ERROR][HISTORY] Failed to save replay %s: %v", record.ID, err)
+		record.HasReplay = false
+	}
+
+	record, err := model.RecordMatch(record)
+	if err != nil {
+		log.Printf("[ERROR][HISTORY] Failed to record match %s vs %s: %v",
+			winner.User.Username, loser.User.Username, err)
+		return
+	}
+
+	log.Printf("[INFO][HISTORY] Recorded %s match %s (%s)", record.Mode, record.ID, record.EndReason)
+	payload := utils.Response{
+		Type:    "match_recorded",
+		Success: true,
+		Message: "Match saved to history",
+		Data:    record,
+	}
+	sendToClient(g.Player1.User.Username, payload)
+	sendToClient(g.Player2.User.Username, payload)
+}
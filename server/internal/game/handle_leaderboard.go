@@ -0,0 +1,187 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 100
+	seasonCheckInterval     = time.Minute
+)
+
+var seasonSchedulerOnce sync.Once
+
+func HandleGetLeaderboard(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.LeaderboardRequest
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			utils.WriteMessage(conn, utils.Response{
+				Type:    "leaderboard_response",
+				Success: false,
+				Message: invalidRequestMessage,
+			})
+			return
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+	limit = min(limit, maxLeaderboardLimit)
+
+	var (
+		entries []model.LeaderboardEntry
+		total   int
+		err     error
+	)
+	switch req.Scope {
+	case "", "global":
+		req.Scope = "global"
+		entries, total, err = model.Leaderboard(req.Offset, limit)
+	case "friends":
+		username := ClientUsername(conn)
+		if username == "" {
+			utils.WriteMessage(conn, utils.Response{
+				Type:    "leaderboard_response",
+				Success: false,
+				Message: "Login required",
+			})
+			return
+		}
+		entries, total, err = model.FriendsLeaderboard(username, req.Offset, limit)
+	default:
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "leaderboard_response",
+			Success: false,
+			Message: "Unknown leaderboard scope",
+		})
+		return
+	}
+
+	if err != nil {
+		log.Printf("[ERROR][LEADERBOARD] Failed to load %s leaderboard: %v", req.Scope, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "leaderboard_response",
+			Success: false,
+			Message: "Failed to load leaderboard",
+		})
+		return
+	}
+
+	payload := map[string]interface{}{
+		"scope":   req.Scope,
+		"entries": entries,
+		"total":   total,
+		"offset":  req.Offset,
+		"limit":   limit,
+	}
+	if me, ok := model.LeaderboardRank(ClientUsername(conn)); ok {
+		payload["me"] = me
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "leaderboard_response",
+		Success: true,
+		Message: "Leaderboard loaded",
+		Data:    payload,
+	})
+}
+
+func HandleGetSeason(conn *websocket.Conn, data json.RawMessage) {
+	season, err := model.CurrentSeason()
+	if err != nil {
+		log.Printf("[ERROR][SEASON] Failed to load season: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "season_response",
+			Success: false,
+			Message: "Failed to load season",
+		})
+		return
+	}
+
+	payload := map[string]interface{}{
+		"season": season,
+		"arenas": model.Arenas(),
+	}
+	if user, ok := model.FindUserByUsername(ClientUsername(conn)); ok {
+		payload["trophies"] = user.Trophies
+		payload["best_trophies"] = user.BestTrophies
+		payload["arena"] = model.ArenaFor(user.Trophies)
+		payload["last_season"] = user.LastSeason
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "season_response",
+		Success: true,
+		Message: "Season loaded",
+		Data:    payload,
+	})
+}
+
+// StartSeasonScheduler periodically closes finished seasons and tells the
+// connected players what they were awarded.
+func StartSeasonScheduler() {
+	seasonSchedulerOnce.Do(func() {
+		go func() {
+			checkSeason()
+			ticker := time.NewTicker(seasonCheckInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				checkSeason()
+			}
+		}()
+	})
+}
+
+func checkSeason() {
+	season, results, err := model.EndSeasonIfDue(time.Now())
+	if err != nil {
+		log.Printf("[ERROR][SEASON] Season check failed: %v", err)
+		return
+	}
+	if results == nil {
+		return
+	}
+
+	log.Printf("[INFO][SEASON] Season %d started, %d players rewarded", season.Number, len(results))
+	for username, result := range results {
+		sendToClient(username, utils.Response{
+			Type:    "season_ended",
+			Success: true,
+			Message: "The season has ended",
+			Data: map[string]interface{}{
+				"result": result,
+				"season": season,
+			},
+		})
+	}
+}
+
+// updateTrophies moves both players on the ladder and pushes the change.
+func updateTrophies(winner, loser string, isDraw bool) map[string]model.TrophyChange {
+	changes, err := model.ApplyTrophies(winner, loser, isDraw)
+	if err != nil {
+		log.Printf("[ERROR][TROPHY] Failed to update trophies for %s vs %s: %v", winner, loser, err)
+		return nil
+	}
+
+	for username, change := range changes {
+		sendToClient(username, utils.Response{
+			Type:    "trophy_update",
+			Success: true,
+			Message: "Trophies updated",
+			Data:    change,
+		})
+	}
+	return changes
+}
@@ -10,10 +10,14 @@ import (
 )
 
 func HandleLeaveGame(conn *websocket.Conn, data json.RawMessage) {
+	if rejectSpectator(conn, "leave_game_response") {
+		return
+	}
+
 	var req utils.GameRequest
 
-	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Username == "" {
-		conn.WriteJSON(utils.Response{
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" {
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "leave_game_response",
 			Success: false,
 			Message: "",
@@ -21,9 +25,15 @@ func HandleLeaveGame(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	room, found := rooms[req.RoomID]
-	if !found {
-		conn.WriteJSON(utils.Response{
+	// The player leaving is the user logged in on the connection
+	leaveGame(conn, req.RoomID, ClientUsername(conn))
+}
+
+// leaveGame ends the match in roomID as a forfeit by username.
+func leaveGame(conn *websocket.Conn, roomID, username string) {
+	room := GetRoom(roomID)
+	if room == nil {
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "leave_game_response",
 			Success: false,
 			Message: "Room not found",
@@ -39,15 +49,21 @@ func HandleLeaveGame(conn *websocket.Conn, data json.RawMessage) {
 
 	var winner *model.Player
 
-	if player1 != nil && player1.User.Username == req.Username {
+	if player1 != nil && player1.User.Username == username {
 		winner = player2
-	} else if player2 != nil && player2.User.Username == req.Username {
+	} else if player2 != nil && player2.User.Username == username {
 		winner = player1
+	} else {
+		log.Printf("[WARN][LEAVE] %s is not in room %s", username, roomID)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "leave_game_response",
+			Success: false,
+			Message: "You are not part of this match",
+		})
+		return
 	}
 
-	if winner != nil {
-		room.Game.SetWinner(winner)
-
+	if room.Game.SetWinner(winner) {
 		payload := utils.Response{
 			Type:    "game_over_response",
 			Success: true,
@@ -58,13 +74,14 @@ func HandleLeaveGame(conn *websocket.Conn, data json.RawMessage) {
 		}
 
 		sendToClient(winner.User.Username, payload)
+		sendToSpectators(room.ID, payload)
 	}
 
 	if room.Game.TurnTimerCancel != nil {
 		room.Game.TurnTimerCancel()
 	}
 
-	conn.WriteJSON(utils.Response{
+	utils.WriteMessage(conn, utils.Response{
 		Type:    "leave_game_response",
 		Success: true,
 		Message: "Left room and winner set if applicable",
@@ -82,15 +99,14 @@ func HandleDisconnect(conn *websocket.Conn) {
 	if roomID == "" {
 		return
 	}
+	if room := GetRoom(roomID); room != nil && room.Game != nil && room.Game.Paused {
+		// A paused match waits for the player to reattach
+		return
+	}
 
 	log.Printf("[INFO] %s disconnected, handling leave...", username)
 
-	req := utils.GameRequest{
-		RoomID:   roomID,
-		Username: username,
-	}
-	raw, _ := json.Marshal(req)
-	HandleLeaveGame(conn, raw)
+	leaveGame(conn, roomID, username)
 }
 
 
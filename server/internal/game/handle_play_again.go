@@ -9,11 +9,15 @@ import (
 )
 
 func HandlePlayAgain(conn *websocket.Conn, data json.RawMessage) {
+	if rejectSpectator(conn, "play_again_response") {
+		return
+	}
+
 	var req utils.GameOverRequest
 
 	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" {
 		log.Printf("[WARN][PLAY_AGAIN] Invalid request: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "play_again_response",
 			Success: false,
 			Message: invalidRequestMessage,
@@ -26,7 +30,7 @@ func HandlePlayAgain(conn *websocket.Conn, data json.RawMessage) {
 	roomsMu.RUnlock()
 	if !exists {
 		log.Printf("[WARN][PLAY_AGAIN] Room %s not found", req.RoomID)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "play_again_response",
 			Success: false,
 			Message: roomRequestMessage,
@@ -37,6 +41,7 @@ func HandlePlayAgain(conn *websocket.Conn, data json.RawMessage) {
 	roomsMu.Lock()
 	delete(rooms, room.ID)
 	roomsMu.Unlock()
+	clearSpectators(room.ID)
 
 	log.Printf("[INFO][PLAY_AGAIN] Room %s cleaned up", room.ID)
 }
\ No newline at end of file
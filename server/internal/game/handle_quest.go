@@ -0,0 +1,59 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func HandleGetQuests(conn *websocket.Conn, data json.RawMessage) {
+	username := ClientUsername(conn)
+	user, ok := model.FindUserByUsername(username)
+	if username == "" || !ok {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "quests_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	now := time.Now()
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "quests_response",
+		Success: true,
+		Message: "Quests loaded",
+		Data: map[string]interface{}{
+			"quests":       user.QuestStatus(now),
+			"achievements": user.AchievementStatus(),
+			"resets_at":    model.NextShopReset(now),
+		},
+	})
+}
+
+// sendProgress records a player's match stats against their quests and
+// achievements, then pushes the updated progress to them.
+func sendProgress(player *model.Player) {
+	username := player.User.Username
+	report, err := model.ApplyMatchStats(username, player.Stats)
+	if err != nil {
+		log.Printf("[ERROR][QUEST] Failed to update progress for %s: %v", username, err)
+		return
+	}
+
+	if len(report.Completed) > 0 || len(report.TiersReached) > 0 {
+		log.Printf("[INFO][QUEST] %s completed %v, reached %v (+%d EXP, +%d gold)",
+			username, report.Completed, report.TiersReached, report.RewardEXP, report.RewardGold)
+	}
+
+	sendToClient(username, utils.Response{
+		Type:    "quest_progress",
+		Success: true,
+		Message: "Progress updated",
+		Data:    report,
+	})
+}
@@ -13,11 +13,15 @@ import (
 )
 
 func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
+	if rejectSpectator(conn, "troop_response") {
+		return
+	}
+
 	var req utils.SelectTroopRequest
 
-	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Username == "" || req.Troop == "" {
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Troop == "" {
 		log.Printf("[ERROR][SELECT] Invalid request: %+v", req)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "troop_response",
 			Success: false,
 			Message: "Invalid request",
@@ -25,30 +29,36 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
+	// The player acting is the user logged in on the connection
+	username := ClientUsername(conn)
+
 	log.Printf("[INFO][SELECT] %s is trying to spawn %s at (%f, %f) in room %s",
-		req.Username, req.Troop, req.X, req.Y, req.RoomID)
+		username, req.Troop, req.X, req.Y, req.RoomID)
 
 	roomsMu.RLock()
 	room, ok := rooms[req.RoomID]
 	roomsMu.RUnlock()
 	if !ok {
 		log.Printf("[WARN][SELECT] Room %s not found", req.RoomID)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "troop_response",
 			Success: false,
 			Message: "Room not found",
 		})
 		return
 	}
+	if rejectPaused(conn, room, "troop_response") {
+		return
+	}
 
 	var player *model.Player
-	if room.Player1.User.Username == req.Username {
+	if room.Player1.User.Username == username {
 		player = room.Player1
-	} else if room.Player2.User.Username == req.Username {
+	} else if room.Player2.User.Username == username {
 		player = room.Player2
 	} else {
-		log.Printf("[WARN][SELECT] %s is not in the match", req.Username)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][SELECT] %s is not in the match", username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "troop_response",
 			Success: false,
 			Message: "You are not in this match",
@@ -65,8 +75,8 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 		}
 	}
 	if selectedTemplate == nil {
-		log.Printf("[WARN][SELECT] Troop %s not found in %s's hand", req.Troop, req.Username)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][SELECT] Troop %s not found in %s's hand", req.Troop, username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "troop_response",
 			Success: false,
 			Message: "Troop not in hand",
@@ -76,16 +86,16 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 
 	realX, realY := float64(req.X), float64(req.Y)
 
-	if room.Player1.User.Username == req.Username {
+	if room.Player1.User.Username == username {
 		realX = 21.0 - req.X
 		realY = 21.0 - req.Y
 	}
 
-	log.Printf("[INFO][SPAWN] %s spawned %s at (%f, %f)", req.Username, selectedTemplate.Name, realX, realY)
+	log.Printf("[INFO][SPAWN] %s spawned %s at (%f, %f)", username, selectedTemplate.Name, realX, realY)
 
-	if !room.Game.IsValidSpawnPosition(req.Username, realX, realY) {
-		log.Printf("[WARN][SELECT] Invalid position (%f, %f) for %s", realX, realY, req.Username)
-		conn.WriteJSON(utils.Response{
+	if !room.Game.IsValidSpawnPosition(username, realX, realY) {
+		log.Printf("[WARN][SELECT] Invalid position (%f, %f) for %s", realX, realY, username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "troop_response",
 			Success: false,
 			Message: "Invalid spawn position",
@@ -96,8 +106,8 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 	// Check mana
 	if room.Game.Enhanced && player.Mana < selectedTemplate.MANA {
 		log.Printf("[WARN][SELECT] Not enough mana for %s to use %s (has %d, needs %d)",
-			req.Username, selectedTemplate.Name, player.Mana, selectedTemplate.MANA)
-		conn.WriteJSON(utils.Response{
+			username, selectedTemplate.Name, player.Mana, selectedTemplate.MANA)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "troop_response",
 			Success: false,
 			Message: "Not enough mana",
@@ -105,7 +115,11 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
+	// Snapshots must not see the card played but not yet deployed
+	room.Game.loopMu.Lock()
+
 	player.Mana -= selectedTemplate.MANA
+	player.Stats.CardsPlayed++
 
 	player.RotateTroop(req.Troop)
 
@@ -122,6 +136,7 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 	}
 
 	room.Game.BattleSystem.AddEntity(instance)
+	room.Game.loopMu.Unlock()
 
 	// Gửi lại troop mới spawn cho cả 2
 	payload := utils.Response{
@@ -134,9 +149,8 @@ func HandleSelectTroop(conn *websocket.Conn, data json.RawMessage) {
 		},
 	}
 
-	log.Printf("[INFO][SELECT] Sending troop response to %s", req.Username)
-	sendToClient(room.Player1.User.Username, payload)
-	sendToClient(room.Player2.User.Username, payload)
+	log.Printf("[INFO][SELECT] Sending troop response to %s", username)
+	room.Game.broadcast(payload)
 }
 
 func (g *Game) IsValidSpawnPosition(username string, x, y float64) bool {
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type shopOfferView struct {
+	model.ShopOffer
+	Purchased bool `json:"purchased"`
+}
+
+type chestView struct {
+	model.Chest
+	Unlocked    bool  `json:"unlocked"`
+	SecondsLeft int64 `json:"seconds_left"`
+}
+
+func HandleGetShop(conn *websocket.Conn, data json.RawMessage) {
+	username := ClientUsername(conn)
+	if username == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "shop_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	user, templates, err := model.SyncCollection(username)
+	if err != nil {
+		log.Printf("[ERROR][SHOP] Failed to load shop for %s: %v", username, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "shop_response",
+			Success: false,
+			Message: "Failed to load shop",
+		})
+		return
+	}
+
+	now := time.Now()
+	bought := user.PurchasedToday(now)
+	offers := []shopOfferView{}
+	for _, o := range model.DailyOffers(now, templates) {
+		offers = append(offers, shopOfferView{ShopOffer: o, Purchased: slices.Contains(bought, o.ID)})
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "shop_response",
+		Success: true,
+		Message: "Shop loaded",
+		Data: map[string]interface{}{
+			"gold":      user.Gold,
+			"offers":    offers,
+			"resets_at": model.NextShopReset(now),
+		},
+	})
+}
+
+func HandleBuyOffer(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ShopRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || username == "" || req.OfferID == "" {
+		log.Printf("[WARN][SHOP] invalid purchase request: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "buy_offer_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	user, offer, grant, err := model.BuyOffer(username, req.OfferID)
+	if err != nil {
+		log.Printf("[WARN][SHOP] %s failed to buy %s: %v", username, req.OfferID, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "buy_offer_response",
+			Success: false,
+			Message: shopErrorMessage(err),
+		})
+		return
+	}
+
+	log.Printf("[INFO][SHOP] %s bought %s (%s) for %d gold", username, offer.ID, offer.Card, offer.Price)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "buy_offer_response",
+		Success: true,
+		Message: "Purchase complete",
+		Data: map[string]interface{}{
+			"offer": offer,
+			"card":  grant,
+			"gold":  user.Gold,
+		},
+	})
+}
+
+func HandleGetChests(conn *websocket.Conn, data json.RawMessage) {
+	username := ClientUsername(conn)
+	user, ok := model.FindUserByUsername(username)
+	if username == "" || !ok {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "chests_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "chests_response",
+		Success: true,
+		Message: "Chests loaded",
+		Data: map[string]interface{}{
+			"chests":    chestViews(user.Chests),
+			"max_slots": model.MaxChests,
+		},
+	})
+}
+
+func HandleOpenChest(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChestRequest
+
+	username := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || username == "" || req.ChestID == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "open_chest_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	user, reward, err := model.OpenChest(username, req.ChestID)
+	if err != nil {
+		log.Printf("[WARN][SHOP] %s failed to open chest %s: %v", username, req.ChestID, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "open_chest_response",
+			Success: false,
+			Message: shopErrorMessage(err),
+		})
+		return
+	}
+
+	log.Printf("[INFO][SHOP] %s opened %s chest: %d gold, %d cards", username, reward.Chest.Type, reward.Gold, len(reward.Cards))
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "open_chest_response",
+		Success: true,
+		Message: "Chest opened",
+		Data: map[string]interface{}{
+			"reward": reward,
+			"gold":   user.Gold,
+		},
+	})
+}
+
+// awardChest gives a match winner a chest and tells them about it.
+func awardChest(username string) {
+	chest, awarded, err := model.AwardChest(username)
+	if err != nil {
+		log.Printf("[ERROR][SHOP] Failed to award chest to %s: %v", username, err)
+		return
+	}
+	if !awarded {
+		log.Printf("[INFO][SHOP] %s has no free chest slot", username)
+		return
+	}
+
+	log.Printf("[INFO][SHOP] %s earned a %s chest", username, chest.Type)
+	sendToClient(username, utils.Response{
+		Type:    "chest_earned",
+		Success: true,
+		Message: "You earned a " + chest.Type + " chest!",
+		Data:    chestViews([]model.Chest{chest})[0],
+	})
+}
+
+func chestViews(chests []model.Chest) []chestView {
+	now := time.Now()
+	views := make([]chestView, 0, len(chests))
+	for _, c := range chests {
+		left := int64(0)
+		if !c.Unlocked(now) {
+			left = int64(c.UnlockAt.Sub(now).Seconds()) + 1
+		}
+		views = append(views, chestView{Chest: c, Unlocked: c.Unlocked(now), SecondsLeft: left})
+	}
+	return views
+}
+
+func shopErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, model.ErrOfferNotFound):
+		return "This offer is no longer available"
+	case errors.Is(err, model.ErrOfferPurchased):
+		return "You already bought this offer today"
+	case errors.Is(err, model.ErrCardMaxLevel):
+		return "Card is already at max level"
+	case errors.Is(err, model.ErrNotEnoughGold):
+		return "Not enough gold"
+	case errors.Is(err, model.ErrChestNotFound):
+		return "Chest not found"
+	case errors.Is(err, model.ErrChestLocked):
+		return "Chest is still locked"
+	default:
+		return "Purchase failed"
+	}
+}
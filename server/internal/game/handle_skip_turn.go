@@ -3,16 +3,21 @@ package game
 import (
 	"encoding/json"
 	"log"
+	"royaka/internal/model"
 	"royaka/internal/utils"
 
 	"github.com/gorilla/websocket"
 )
 
 func HandleSkipTurn(conn *websocket.Conn, data json.RawMessage) {
+	if rejectSpectator(conn, "skip_turn_response") {
+		return
+	}
+
 	var req utils.GameRequest
-	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" || req.Username == "" {
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" {
 		log.Printf("[WARN][SKIP_TURN] Invalid request from conn %v: %v | Data: %s", conn.RemoteAddr(), err, string(data))
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "skip_turn_response",
 			Success: false,
 			Message: "Invalid skip turn request",
@@ -20,39 +25,58 @@ func HandleSkipTurn(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
+	// The player acting is the user logged in on the connection
+	username := ClientUsername(conn)
+
 	roomsMu.RLock()
 	room, exists := rooms[req.RoomID]
 	roomsMu.RUnlock()
 
 	if !exists {
-		log.Printf("[WARN][SKIP_TURN] Room not found: %s by user %s", req.RoomID, req.Username)
-		conn.WriteJSON(utils.Response{
+		log.Printf("[WARN][SKIP_TURN] Room not found: %s by user %s", req.RoomID, username)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "skip_turn_response",
 			Success: false,
 			Message: "Room not found",
 		})
 		return
 	}
+	if rejectPaused(conn, room, "skip_turn_response") {
+		return
+	}
 
-	current := room.Game.CurrentPlayer()
-	if current.User.Username != req.Username {
-		log.Printf("[WARN][SKIP_TURN] Not %s's turn in room %s", req.Username, req.RoomID)
-		conn.WriteJSON(utils.Response{
+	var player *model.Player
+	if room.Player1.User.Username == username {
+		player = room.Player1
+	} else if room.Player2.User.Username == username {
+		player = room.Player2
+	} else {
+		log.Printf("[WARN][SKIP_TURN] User %s not in room %s", username, req.RoomID)
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "skip_turn_response",
 			Success: false,
-			Message: "It's not your turn!",
+			Message: "You are not part of this match",
 		})
 		return
 	}
 
-	room.Game.SkipTurn(current)
+	result, err := room.Game.Act(player, TurnAction{Kind: ActionSkip})
+	if err != nil {
+		log.Printf("[WARN][SKIP_TURN] %s's skip in room %s refused: %v", username, req.RoomID, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "skip_turn_response",
+			Success: false,
+			Message: turnErrorMessage(err),
+		})
+		return
+	}
 
 	log.Printf("[DEBUG][SKIP_TURN] Turn switched to: %s", room.Game.Turn)
 
 	payload := utils.Response{
 		Type:    "skip_turn_response",
 		Success: true,
-		Message: "Turn skipped",
+		Message: result.Message,
 		Data: map[string]interface{}{
 			"turn":    room.Game.Turn,
 			"player1": room.Game.Player1,
@@ -60,6 +84,6 @@ func HandleSkipTurn(conn *websocket.Conn, data json.RawMessage) {
 		},
 	}
 
-	sendToClient(room.Player1.User.Username, payload)
-	sendToClient(room.Player2.User.Username, payload)
-}
\ No newline at end of file
+	room.Game.broadcast(payload)
+	room.Game.broadcastTurn(result)
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"royaka/internal/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// HandleBoost boosts a troop in hand for its next attack.
+func HandleBoost(conn *websocket.Conn, data json.RawMessage) {
+	handleTactic(conn, data, ActionBoost, "boost_response")
+}
+
+// HandleFortify fortifies one of the player's towers until their next turn.
+func HandleFortify(conn *websocket.Conn, data json.RawMessage) {
+	handleTactic(conn, data, ActionFortify, "fortify_response")
+}
+
+// HandleSwapCard swaps a troop in hand for the next one in reserve.
+func HandleSwapCard(conn *websocket.Conn, data json.RawMessage) {
+	handleTactic(conn, data, ActionSwap, "swap_card_response")
+}
+
+// handleTactic plays a simple mode action that spends the turn without
+// attacking or healing. The sender gets responseType, both players the
+// turn_result.
+func handleTactic(conn *websocket.Conn, data json.RawMessage, kind, responseType string) {
+	if rejectSpectator(conn, responseType) {
+		return
+	}
+
+	var req utils.TacticRequest
+
+	// Parse & validate request data
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" {
+		log.Printf("[WARN][TURN] invalid %s request: %v", kind, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    responseType,
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	// The player acting is the user logged in on the connection
+	username := ClientUsername(conn)
+
+	// Fetch the room from memory
+	roomsMu.RLock()
+	room, exists := rooms[req.RoomID]
+	roomsMu.RUnlock()
+	if !exists {
+		log.Printf("[WARN][TURN] Room %s not found for %s", req.RoomID, username)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    responseType,
+			Success: false,
+			Message: roomRequestMessage,
+		})
+		return
+	}
+	if rejectPaused(conn, room, responseType) {
+		return
+	}
+
+	// Identify the player
+	player := room.Player1
+	if username != "" && room.Player2.User.Username == username {
+		player = room.Player2
+	} else if username == "" || player.User.Username != username {
+		log.Printf("[WARN][TURN] User %q not in room %s", username, req.RoomID)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    responseType,
+			Success: false,
+			Message: "You are not part of this match",
+		})
+		return
+	}
+
+	result, err := room.Game.Act(player, TurnAction{Kind: kind, Troop: req.Troop, Target: req.Target})
+	if err != nil {
+		log.Printf("[WARN][TURN] %s's %s in room %s refused: %v", username, kind, req.RoomID, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    responseType,
+			Success: false,
+			Message: turnErrorMessage(err),
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    responseType,
+		Success: true,
+		Message: result.Message,
+		Data: map[string]interface{}{
+			"troop":  result.Troop,
+			"target": result.Target,
+			"drawn":  result.Drawn,
+			"hand":   player.Troops,
+			"mana":   player.Mana,
+		},
+	})
+	room.Game.broadcastTurn(result)
+}
@@ -0,0 +1,20 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logger returns a logger carrying the match's room and mode.
+func (g *Game) logger() *slog.Logger {
+	return slog.With("room_id", g.RoomID, "mode", g.Mode())
+}
+
+// combatLog logs a single hit, heal or kill. These run every tick, so they
+// are debug level and skipped entirely unless debug logging is on.
+func (g *Game) combatLog(msg string, args ...any) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	g.logger().Debug(msg, append([]any{"area", "combat"}, args...)...)
+}
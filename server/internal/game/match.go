@@ -2,7 +2,6 @@ package game
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"royaka/internal/model"
 	"royaka/internal/utils"
@@ -11,13 +10,13 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-func HandleFindMatch(conn *websocket.Conn, data json.RawMessage) {
+func (e *Engine) HandleFindMatch(conn *websocket.Conn, data json.RawMessage) {
 	var req utils.FindMatchRequest
 
 	// Parse & validate request data
-	if err := json.Unmarshal(data, &req); err != nil || req.Username == "" || req.Mode == "" {
+	if err := json.Unmarshal(data, &req); err != nil || req.Mode == "" {
 		log.Printf("[WARN][MATCH] invalid request: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "find_match_response",
 			Success: false,
 			Message: invalidRequestMessage,
@@ -25,35 +24,100 @@ func HandleFindMatch(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	username := req.Username
-	log.Printf("[INFO][MATCH] matchmaking request: user=%s, mode=%s", username, req.Mode)
+	// Players queue as the user they logged in as, never as someone else
+	clientConn, username := trackedClient(conn)
+	if clientConn == nil {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: "Login required",
+		})
+		return
+	}
+	if req.Username != "" && req.Username != username {
+		log.Printf("[WARN][MATCH] %s tried to queue as %s", username, req.Username)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: "You can only queue as yourself",
+		})
+		return
+	}
+
+	if Draining() {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: shutdownMessage,
+		})
+		return
+	}
+
+	if _, ok := matchQueues[req.Mode]; !ok {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: "Invalid game mode",
+		})
+		return
+	}
+
+	log.Printf("[INFO][MATCH] matchmaking request: user=%s, mode=%s, deck=%q", username, req.Mode, req.Deck)
+
+	user, ok := model.FindUserByUsername(username)
+	if !ok {
+		log.Printf("[WARN][MATCH] user %s not found", username)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+	if err := user.PlayRestriction(); err != nil {
+		log.Printf("[WARN][MATCH] user %s may not play: %v", username, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: RestrictionMessage(user.Ban),
+		})
+		return
+	}
+	deck, err := playerDeck(&user, req.Mode, req.Deck)
+	if err != nil {
+		log.Printf("[WARN][MATCH] user %s cannot use deck %q: %v", username, req.Deck, err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "find_match_response",
+			Success: false,
+			Message: deckErrorMessage(err),
+		})
+		return
+	}
 
 	// Check if user already in matchmaking queue
 	pendingMu.Lock()
-	if pendingPlayers[username] {
+	if _, queued := pendingPlayers[username]; queued {
 		pendingMu.Unlock()
 		log.Printf("[WARN][MATCH] user %s already in queue", username)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "find_match_response",
 			Success: false,
 			Message: "Already in queue",
 		})
 		return
 	}
-	pendingPlayers[username] = true
+	pendingPlayers[username] = queueEntry{Mode: req.Mode, Since: time.Now()}
 	pendingMu.Unlock()
 
-	// Save client connection for future communication
-	clientConn := &ClientConnection{Conn: conn}
-	clientsMu.Lock()
-	clients[username] = clientConn
-	clientsMu.Unlock()
+	// Queued players stop watching other matches
+	RemoveSpectator(conn)
 
 	// Create Player instance and register
-	user, _ := model.FindUserByUsername(req.Username)
-	player := model.NewPlayer(&user, req.Mode)
+	player := model.NewPlayer(&user, req.Mode, deck)
 	model.RegisterConnection(conn, player)
 
+	go notifyPresence(username)
+
 	// Confirm queue entry
 	clientConn.SafeWrite(utils.Response{
 		Type:    "find_match_response",
@@ -62,18 +126,18 @@ func HandleFindMatch(conn *websocket.Conn, data json.RawMessage) {
 	})
 
 	// Push player to matchmaking queue
-	go queuePlayer(player, clientConn, req.Mode, username)
+	go e.queuePlayer(player, clientConn, req.Mode, username)
 
 	// Start the matchmaker loop once
 	matchmakerOnce.Do(func() {
-		startMatchmaker()
+		e.startMatchmaker()
 		log.Println("[MATCH] matchmaker started")
 	})
 }
 
-func queuePlayer(player *model.Player, clientConn *ClientConnection, mode, username string) {
+func (e *Engine) queuePlayer(player *model.Player, clientConn *ClientConnection, mode, username string) {
 	queue, ok := matchQueues[mode]
-	fmt.Printf("[INFO][MATCH] player queued for mode %s (queue: %v)\n", mode, matchQueues[mode])
+	log.Printf("[INFO][MATCH] %s queued for mode %s", username, mode)
 
 	if !ok {
 		log.Printf("[WARN][MATCH] invalid mode %s for user %s", mode, username)
@@ -85,20 +149,25 @@ func queuePlayer(player *model.Player, clientConn *ClientConnection, mode, usern
 		return
 	}
 
+	timeout := time.Duration(e.RulesFor(mode).MatchmakingTimeout)
+
 	select {
 	case queue <- player:
-	case <-time.After(30 * time.Second):
+	case <-time.After(timeout):
 		log.Printf("[WARN][MATCH] enqueue timeout for user %s", username)
 		CleanupUser(username)
 		return
 	}
 
-	timer := time.NewTimer(30 * time.Second)
+	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
-	case <-player.Matched:
-		log.Printf("[INFO][MATCH] user %s matched", username)
+	case matched := <-player.Matched:
+		// false when the player was taken out of matchmaking
+		if matched {
+			log.Printf("[INFO][MATCH] user %s matched", username)
+		}
 	case <-timer.C:
 		log.Printf("[WARN][MATCH] matchmaking timeout for user %s", username)
 		RemovePlayerFromQueue(player)
@@ -141,32 +210,31 @@ func RemovePlayerFromQueue(targetPlayer *model.Player) {
 	}
 }
 
+// CleanupUser drops the user's matchmaking state. The connection itself
+// stays tracked until it closes so the user remains reachable.
 func CleanupUser(username string) {
 	pendingMu.Lock()
 	delete(pendingPlayers, username)
 	pendingMu.Unlock()
 
-	clientsMu.Lock()
-	delete(clients, username)
-	clientsMu.Unlock()
-
 	log.Printf("[INFO][CLEANUP] removed user %s from queues", username)
+	go notifyPresence(username)
 }
 
-func startMatchmaker() {
+func (e *Engine) startMatchmaker() {
 	go func() {
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
 
 		for range ticker.C {
 			for mode, queue := range matchQueues {
-				if len(queue) < 2 {
+				if len(queue) < 2 || Draining() {
 					continue
 				}
 				player1, player2 := <-queue, <-queue
 				log.Printf("[INFO][MATCH] pairing players in mode %s: %s vs %s", mode, player1.User.Username, player2.User.Username)
 				if validatePlayers(player1, player2, mode) {
-					handleMatch(player1, player2, mode)
+					e.handleMatch(player1, player2, mode, e.RulesFor(mode))
 				}
 			}
 		}
@@ -193,7 +261,7 @@ func validatePlayers(p1, p2 *model.Player, mode string) bool {
 	return true
 }
 
-func handleMatch(p1, p2 *model.Player, mode string) {
+func (e *Engine) handleMatch(p1, p2 *model.Player, mode string, rules model.MatchRules) {
 	clientsMu.RLock()
 	conn1 := clients[p1.User.Username]
 	conn2 := clients[p2.User.Username]
@@ -205,7 +273,7 @@ func handleMatch(p1, p2 *model.Player, mode string) {
 
 	// Create and register room
 	roomID := utils.GenerateRoomID()
-	room := NewRoom(roomID, p1, p2, mode)
+	room := NewRoom(roomID, p1, p2, mode, rules, e.tickRate)
 
 	roomsMu.Lock()
 	rooms[roomID] = room
@@ -215,17 +283,20 @@ func handleMatch(p1, p2 *model.Player, mode string) {
 	log.Printf("[INFO][ROOM] created room %s with players %s and %s", roomID, p1.User.Username, p2.User.Username)
 
 	// Notify players
-	notifyMatchFound(conn1, p2.User.Username, roomID)
-	notifyMatchFound(conn2, p1.User.Username, roomID)
+	notifyMatchFound(conn1, p2.User.Username, roomID, rules)
+	notifyMatchFound(conn2, p1.User.Username, roomID, rules)
 
 	// Remove from pending
 	pendingMu.Lock()
 	delete(pendingPlayers, p1.User.Username)
 	delete(pendingPlayers, p2.User.Username)
 	pendingMu.Unlock()
+
+	go notifyPresence(p1.User.Username)
+	go notifyPresence(p2.User.Username)
 }
 
-func notifyMatchFound(conn *ClientConnection, opponent, roomID string) {
+func notifyMatchFound(conn *ClientConnection, opponent, roomID string, rules model.MatchRules) {
 	conn.SafeWrite(utils.Response{
 		Type:    "match_found",
 		Success: true,
@@ -233,6 +304,7 @@ func notifyMatchFound(conn *ClientConnection, opponent, roomID string) {
 		Data: map[string]interface{}{
 			"room_id":  roomID,
 			"opponent": opponent,
+			"rules":    rules,
 		},
 	})
 }
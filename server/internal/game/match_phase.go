@@ -0,0 +1,109 @@
+package game
+
+import (
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"time"
+)
+
+// Enhanced matches that are tied on towers when time runs out go to
+// overtime, then sudden death, and only then to a tower health tiebreak.
+const (
+	PhaseRegular     = "regular"
+	PhaseOvertime    = "overtime"
+	PhaseSuddenDeath = "sudden_death"
+)
+
+// PhaseEndsAt is when the current phase of an enhanced match runs out.
+func (g *Game) PhaseEndsAt() time.Time {
+	switch g.Phase {
+	case PhaseOvertime:
+		return g.PhaseStarted.Add(time.Duration(g.Rules.Overtime))
+	case PhaseSuddenDeath:
+		return g.PhaseStarted.Add(time.Duration(g.Rules.SuddenDeath))
+	default:
+		return g.StartTime.Add(g.MaxTime)
+	}
+}
+
+// manaRegenInterval doubles mana regeneration once regular time is over.
+func (g *Game) manaRegenInterval() time.Duration {
+	if g.Phase != PhaseRegular {
+		return time.Duration(g.Rules.ManaRegen) / 2
+	}
+	return time.Duration(g.Rules.ManaRegen)
+}
+
+// advancePhase moves a tied match to its next phase and announces it. It
+// returns false when there is no phase left to play.
+func (g *Game) advancePhase() bool {
+	next := ""
+	switch g.Phase {
+	case PhaseRegular:
+		if g.Rules.Overtime > 0 {
+			next = PhaseOvertime
+		} else if g.Rules.SuddenDeath > 0 {
+			next = PhaseSuddenDeath
+		}
+	case PhaseOvertime:
+		if g.Rules.SuddenDeath > 0 {
+			next = PhaseSuddenDeath
+		}
+	}
+	if next == "" {
+		return false
+	}
+
+	g.Phase = next
+	g.PhaseStarted = time.Now()
+
+	message := "Overtime! Mana regenerates twice as fast"
+	if next == PhaseSuddenDeath {
+		message = "Sudden death! The next tower destroyed wins"
+	}
+	log.Printf("[INFO][GAME] Room %s entered %s", g.RoomID, next)
+
+	g.broadcast(utils.Response{
+		Type:    "phase_change",
+		Success: true,
+		Message: message,
+		Data: map[string]interface{}{
+			"phase":    next,
+			"timeLeft": time.Until(g.PhaseEndsAt()).Milliseconds(),
+		},
+	})
+	return true
+}
+
+// tiebreak ends a match still tied after sudden death: the player whose
+// weakest tower has less health left loses.
+func (g *Game) tiebreak() (*model.Player, string) {
+	p1 := g.Player1.WeakestTowerPercent()
+	p2 := g.Player2.WeakestTowerPercent()
+
+	if p1 > p2 {
+		return g.finish(g.Player1, g.Player2, model.EndTiebreak, " wins on tower health!")
+	}
+	if p2 > p1 {
+		return g.finish(g.Player2, g.Player1, model.EndTiebreak, " wins on tower health!")
+	}
+
+	g.WinnerDeclared = true
+	g.EndReason = model.EndTimeout
+	g.StopGameLoop()
+	g.AwardEXP(g.Player1, g.Player2, true)
+	g.logger().Info("Match drawn", "area", "match", "reason", g.EndReason)
+	return nil, "It's a draw!"
+}
+
+// finish ends an enhanced match decided by score, sudden death or tiebreak.
+func (g *Game) finish(winner, loser *model.Player, reason, suffix string) (*model.Player, string) {
+	g.WinnerDeclared = true
+	g.EndReason = reason
+	g.StopGameLoop()
+
+	g.AwardEXP(winner, loser, false)
+	g.logger().Info("Match won", "area", "match", "winner", winner.User.Username, "reason", reason)
+	return winner, winner.User.Username + suffix
+}
@@ -0,0 +1,31 @@
+package game
+
+import "royaka/internal/metrics"
+
+var (
+	matchesStarted = metrics.NewCounter("royaka_matches_started_total",
+		"Matches started, by mode.", "mode")
+	matchesFinished = metrics.NewCounter("royaka_matches_finished_total",
+		"Matches finished, by mode and end reason.", "mode", "reason")
+	tickDuration = metrics.NewHistogram("royaka_tick_duration_seconds",
+		"Time spent updating the battle map of a timed match per tick.", metrics.DefBuckets)
+	broadcastBytes = metrics.NewCounter("royaka_broadcast_bytes_total",
+		"Bytes pushed to players and spectators, by message type.", "type")
+	sendFailures = metrics.NewCounter("royaka_send_failures_total",
+		"Pushes that could not be delivered, by reason.", "reason")
+)
+
+func init() {
+	metrics.NewGaugeVec("royaka_queue_length", "Players waiting in the matchmaking queue, by mode.", "mode",
+		func() map[string]float64 {
+			lengths := make(map[string]float64, len(matchQueues))
+			for mode, queue := range matchQueues {
+				lengths[mode] = float64(len(queue))
+			}
+			return lengths
+		})
+	metrics.NewGauge("royaka_rooms_active", "Rooms with a match that has not ended.",
+		func() float64 {
+			return float64(len(liveGames()))
+		})
+}
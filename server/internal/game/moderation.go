@@ -0,0 +1,145 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// reportWindow is how long after a match its players can report it.
+	reportWindow     = 24 * time.Hour
+	maxReportDetails = 500
+	untilFormat      = "2006-01-02 15:04 MST"
+)
+
+// ==== REPORTS ====
+
+// HandleReportPlayer files a report against the sender's opponent in one
+// of their recently finished matches.
+func HandleReportPlayer(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ReportPlayerRequest
+
+	reporter := ClientUsername(conn)
+	if err := json.Unmarshal(data, &req); err != nil || req.MatchID == "" || reporter == "" {
+		writeReportError(conn, invalidRequestMessage)
+		return
+	}
+
+	req.Details = strings.TrimSpace(req.Details)
+	if !slices.Contains(model.ReportReasons, req.Reason) {
+		writeReportError(conn, "Reason must be one of: "+strings.Join(model.ReportReasons, ", "))
+		return
+	}
+	if utf8.RuneCountInString(req.Details) > maxReportDetails {
+		writeReportError(conn, "Details are too long")
+		return
+	}
+
+	match, err := model.FindMatch(req.MatchID)
+	if err == nil {
+		if _, played := match.Player(reporter); !played {
+			err = model.ErrMatchNotFound
+		}
+	}
+	if err != nil {
+		writeReportError(conn, "Match not found")
+		return
+	}
+	if time.Since(match.EndedAt) > reportWindow {
+		writeReportError(conn, "This match is too old to report")
+		return
+	}
+
+	var reported string
+	for _, p := range match.Players {
+		if p.Username != reporter {
+			reported = p.Username
+		}
+	}
+
+	report, err := model.AddReport(model.Report{
+		MatchID:  match.ID,
+		Reporter: reporter,
+		Reported: reported,
+		Reason:   req.Reason,
+		Details:  req.Details,
+	})
+	if errors.Is(err, model.ErrAlreadyReported) {
+		writeReportError(conn, "You already reported this match")
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR][REPORT] Saving report from %s failed: %v", reporter, err)
+		writeReportError(conn, "Failed to save report")
+		return
+	}
+
+	log.Printf("[INFO][REPORT] %s reported %s for %s in match %s", reporter, reported, req.Reason, match.ID)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "report_player_response",
+		Success: true,
+		Message: "Report received. Thank you.",
+		Data:    map[string]string{"report_id": report.ID},
+	})
+}
+
+func writeReportError(conn *websocket.Conn, message string) {
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "report_player_response",
+		Success: false,
+		Message: message,
+	})
+}
+
+// ==== RESTRICTIONS ====
+
+// RestrictionMessage explains to a player why their account may not log
+// in or play.
+func RestrictionMessage(ban *model.Ban) string {
+	message := "Account is banned"
+	if ban.IsSuspension() {
+		message = "Account is suspended"
+	}
+	if ban != nil && !ban.Until.IsZero() {
+		message += " until " + ban.Until.UTC().Format(untilFormat)
+	}
+	if ban != nil && ban.Reason != "" {
+		message += ": " + ban.Reason
+	}
+	return message
+}
+
+// NotifySuspension tells a connected user about their suspension and takes
+// them out of matchmaking. Matches already running are left to finish.
+func NotifySuspension(username string, ban model.Ban) {
+	pendingMu.RLock()
+	_, queued := pendingPlayers[username]
+	pendingMu.RUnlock()
+	if queued {
+		if player := model.GetPlayerByConn(model.GetConnByUsername(username)); player != nil {
+			RemovePlayerFromQueue(player)
+			// Stop the player's queue wait without a timeout message
+			select {
+			case player.Matched <- false:
+			default:
+			}
+		}
+		CleanupUser(username)
+	}
+
+	sendToClient(username, utils.Response{
+		Type:    "account_suspended",
+		Success: false,
+		Message: RestrictionMessage(&ban),
+		Data:    ban,
+	})
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"sync"
+	"time"
+)
+
+// Enhanced matches broadcast game_state every tick; replays keep one per
+// interval, which is enough to redraw the board.
+const replayStateInterval = time.Second
+
+type replayRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	lastState time.Time
+	events    []model.ReplayEvent
+	chat      []ChatMessage
+}
+
+func newReplayRecorder() *replayRecorder {
+	return &replayRecorder{start: time.Now()}
+}
+
+// record stores a copy of a room broadcast. The data is encoded right away
+// so later changes to the game do not alter the recorded event.
+func (r *replayRecorder) record(payload utils.Response) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if payload.Type == "game_state" {
+		if now.Sub(r.lastState) < replayStateInterval {
+			return
+		}
+		r.lastState = now
+	}
+
+	data, err := json.Marshal(payload.Data)
+	if err != nil {
+		log.Printf("[WARN][REPLAY] Failed to record %s: %v", payload.Type, err)
+		return
+	}
+
+	r.events = append(r.events, model.ReplayEvent{
+		At:      now.Sub(r.start).Milliseconds(),
+		Type:    payload.Type,
+		Message: payload.Message,
+		Data:    data,
+	})
+}
+
+func (r *replayRecorder) recordChat(msg ChatMessage) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chat = append(r.chat, msg)
+}
+
+// build assembles the replay, ending with the match record itself.
+func (r *replayRecorder) build(record model.MatchRecord) model.Replay {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	players := make([]string, 0, len(record.Players))
+	for _, p := range record.Players {
+		players = append(players, p.Username)
+	}
+
+	events := append([]model.ReplayEvent(nil), r.events...)
+	if data, err := json.Marshal(record); err == nil {
+		events = append(events, model.ReplayEvent{
+			At:   record.EndedAt.Sub(r.start).Milliseconds(),
+			Type: "match_end",
+			Data: data,
+		})
+	}
+
+	chat, err := json.Marshal(r.chat)
+	if err != nil || r.chat == nil {
+		chat = json.RawMessage("[]")
+	}
+
+	return model.Replay{
+		MatchID:   record.ID,
+		Mode:      record.Mode,
+		StartedAt: r.start,
+		Players:   players,
+		Events:    events,
+		Chat:      chat,
+	}
+}
@@ -5,6 +5,7 @@ package game
 import (
 	"royaka/internal/model"
 	"sync"
+	"time"
 )
 
 type Room struct {
@@ -12,6 +13,7 @@ type Room struct {
 	Player1 *model.Player
 	Player2 *model.Player
 	Game    *Game
+	ChatLog []ChatMessage
 	mu      sync.Mutex
 }
 
@@ -20,12 +22,16 @@ var (
 	roomLock     sync.RWMutex
 )
 
-func NewRoom(id string, p1, p2 *model.Player, mode string) *Room {
+func NewRoom(id string, p1, p2 *model.Player, mode string, rules model.MatchRules, tickRate time.Duration) *Room {
+	game := NewGame(p1, p2, mode, rules, tickRate)
+	game.RoomID = id
+	matchesStarted.Inc(mode)
+
 	return &Room{
 		ID:      id,
 		Player1: p1,
 		Player2: p2,
-		Game:    NewGame(p1, p2, mode),
+		Game:    game,
 	}
 }
 
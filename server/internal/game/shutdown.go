@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	shutdownMessage   = "Server is shutting down"
+	shutdownPollEvery = 500 * time.Millisecond
+)
+
+// draining is set once shutdown starts; no new matches are made after it.
+var draining atomic.Bool
+
+func Draining() bool {
+	return draining.Load()
+}
+
+// BeginShutdown stops matchmaking, empties the queues and tells every
+// connected client when the server will go away.
+func BeginShutdown(deadline time.Time) {
+	if !draining.CompareAndSwap(false, true) {
+		return
+	}
+
+	for mode, queue := range matchQueues {
+		for len(queue) > 0 {
+			select {
+			case player := <-queue:
+				log.Printf("[INFO][SHUTDOWN] removed %s from %s queue", player.User.Username, mode)
+				CleanupUser(player.User.Username)
+			default:
+			}
+		}
+	}
+
+	clientsMu.RLock()
+	usernames := make([]string, 0, len(clients))
+	for username := range clients {
+		usernames = append(usernames, username)
+	}
+	clientsMu.RUnlock()
+
+	for _, username := range usernames {
+		sendToClient(username, utils.Response{
+			Type:    "server_shutdown",
+			Success: true,
+			Message: shutdownMessage,
+			Data: map[string]interface{}{
+				"deadline": deadline,
+				"in_match": activeRoomID(username) != "",
+			},
+		})
+	}
+	log.Printf("[INFO][SHUTDOWN] Draining, %d clients notified", len(usernames))
+}
+
+// liveGames lists the matches that have not ended yet.
+func liveGames() []*Game {
+	roomsMu.RLock()
+	defer roomsMu.RUnlock()
+
+	var games []*Game
+	for _, room := range rooms {
+		if room.Game != nil && !room.Game.WinnerDeclared {
+			games = append(games, room.Game)
+		}
+	}
+	return games
+}
+
+// WaitForMatches blocks until every running match has ended or ctx is done,
+// and returns how many are still running.
+func WaitForMatches(ctx context.Context) int {
+	ticker := time.NewTicker(shutdownPollEvery)
+	defer ticker.Stop()
+
+	for {
+		live := 0
+		for _, g := range liveGames() {
+			// Restored matches waiting for their players cannot finish
+			if !g.Paused {
+				live++
+			}
+		}
+		if live == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return live
+		case <-ticker.C:
+		}
+	}
+}
+
+// AbortMatches ends the matches still running as draws so both players get
+// the match recorded instead of losing it when the server stops.
+func AbortMatches() {
+	for _, g := range liveGames() {
+		g.abort(model.EndShutdown, "Match ended: "+shutdownMessage)
+	}
+}
+
+// abort ends the match as a draw recorded with the given end reason and
+// reports whether it did; a match that already has a result is left
+// untouched. It must not be called with the turn or loop lock held.
+func (g *Game) abort(reason, message string) bool {
+	unlock := g.lockMatch()
+	if g.WinnerDeclared {
+		unlock()
+		return false
+	}
+	g.WinnerDeclared = true
+	g.EndReason = reason
+	g.Paused = false
+	g.StopGameLoop()
+	if g.TurnTimerCancel != nil {
+		g.TurnTimerCancel()
+	}
+	g.AwardEXP(g.Player1, g.Player2, true)
+	unlock()
+
+	log.Printf("[INFO][MATCH] Ended match in room %s as a draw (%s)", g.RoomID, reason)
+	g.broadcast(utils.Response{
+		Type:    "game_over_response",
+		Success: true,
+		Message: message,
+		Data: map[string]interface{}{
+			"winner": nil,
+		},
+	})
+	return true
+}
@@ -0,0 +1,525 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// SnapshotVersion is bumped whenever the snapshot layout changes; snapshots
+// of another version are not restored.
+const SnapshotVersion = 1
+
+// Restored matches wait this long for both players to come back.
+const reattachTimeout = 2 * time.Minute
+
+var snapshotterOnce sync.Once
+
+// Snapshot is the durable state of a running match. Clocks are stored as
+// time already played so the time the server was down does not count.
+// Combat rolls are drawn from crypto/rand, so there is no RNG state to keep.
+type Snapshot struct {
+	Version        int                    `json:"version"`
+	SavedAt        time.Time              `json:"saved_at"`
+	RoomID         string                 `json:"room_id"`
+	Mode           string                 `json:"mode"`
+	Rules          model.MatchRules       `json:"rules"`
+	Turn           string                 `json:"turn"`
+	TurnNumber     int                    `json:"turn_number,omitempty"` // simple mode
+	ElapsedMs      int64                  `json:"elapsed_ms"`
+	Phase          string                 `json:"phase"`
+	PhaseElapsedMs int64                  `json:"phase_elapsed_ms"`
+	Players        []PlayerSnapshot       `json:"players"` // player 1 first
+	Troops         []*model.TroopInstance `json:"troops"`  // alive on the battle map
+	Towers         []TowerSnapshot        `json:"towers"`
+	ReplayMs       int64                  `json:"replay_ms"`
+	Replay         []model.ReplayEvent    `json:"replay"`
+	Chat           []ChatMessage          `json:"chat"`
+}
+
+type PlayerSnapshot struct {
+	Username string                  `json:"username"`
+	Mana     int                     `json:"mana"`
+	Turn     int                     `json:"turn"`
+	Gold     int                     `json:"gold"`
+	Towers   map[string]*model.Tower `json:"towers"`
+	Hand     []*model.Troop          `json:"hand"`
+	Queue    []*model.Troop          `json:"queue"`
+	Deck     []string                `json:"deck"`
+	Stats    model.MatchStats        `json:"stats"`
+}
+
+type TowerSnapshot struct {
+	ID          string     `json:"id"`
+	Owner       string     `json:"owner"`
+	Type        string     `json:"type"` // key in the owner's towers
+	Area        model.Area `json:"area"`
+	IsDestroyed bool       `json:"is_destroyed"`
+}
+
+// ==== SAVING ====
+
+// StartSnapshots saves every running match each interval.
+func StartSnapshots(interval time.Duration) {
+	snapshotterOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				for _, g := range liveGames() {
+					if !g.Paused {
+						g.saveSnapshot()
+					}
+				}
+			}
+		}()
+	})
+}
+
+func (g *Game) snapshot() Snapshot {
+	now := time.Now()
+	if g.Paused {
+		// The clocks stopped when the match was paused
+		now = g.pausedAt
+	}
+	s := Snapshot{
+		Version:    SnapshotVersion,
+		SavedAt:    now,
+		RoomID:     g.RoomID,
+		Mode:       g.Mode(),
+		Rules:      g.Rules,
+		Turn:       g.Turn,
+		TurnNumber: g.TurnNumber,
+		ElapsedMs:  max(0, now.Sub(g.StartTime).Milliseconds()),
+		Phase:      g.Phase,
+	}
+	if !g.PhaseStarted.IsZero() {
+		s.PhaseElapsedMs = max(0, now.Sub(g.PhaseStarted).Milliseconds())
+	}
+
+	for _, p := range []*model.Player{g.Player1, g.Player2} {
+		s.Players = append(s.Players, PlayerSnapshot{
+			Username: p.User.Username,
+			Mana:     p.Mana,
+			Turn:     p.Turn,
+			Gold:     p.Gold,
+			Towers:   p.Towers,
+			Hand:     p.Troops,
+			Queue:    p.TroopQueue,
+			Deck:     p.Deck,
+			Stats:    p.Stats,
+		})
+	}
+
+	for _, e := range g.BattleSystem.GetEntities() {
+		switch e := e.(type) {
+		case *model.TroopInstance:
+			if e.IsAlive() {
+				s.Troops = append(s.Troops, e)
+			}
+		case *model.TowerInstance:
+			s.Towers = append(s.Towers, TowerSnapshot{
+				ID:          e.ID,
+				Owner:       e.Owner,
+				Type:        e.Template.Type,
+				Area:        e.Area,
+				IsDestroyed: e.IsDestroyed,
+			})
+		}
+	}
+
+	g.replay.mu.Lock()
+	s.ReplayMs = now.Sub(g.replay.start).Milliseconds()
+	s.Replay = append([]model.ReplayEvent(nil), g.replay.events...)
+	s.Chat = append([]ChatMessage(nil), g.replay.chat...)
+	g.replay.mu.Unlock()
+
+	return s
+}
+
+// encodeSnapshot marshals the match between two turns, or two ticks of the
+// enhanced game loop, so it never saves an action half applied.
+func (g *Game) encodeSnapshot() ([]byte, error) {
+	defer g.lockMatch()()
+	return json.Marshal(g.snapshot())
+}
+
+// lockMatch takes the lock the match is played under, the game loop's in
+// enhanced mode and the turn lock in simple mode, and returns its unlock.
+func (g *Game) lockMatch() func() {
+	if g.Enhanced {
+		g.loopMu.Lock()
+		return g.loopMu.Unlock
+	}
+	g.turnMu.Lock()
+	return g.turnMu.Unlock
+}
+
+// saveSnapshot writes the match to disk unless it has already ended.
+func (g *Game) saveSnapshot() error {
+	// Encoded first: ending the match drops the snapshot while holding the
+	// turn or loop lock
+	data, err := g.encodeSnapshot()
+
+	g.snapshotMu.Lock()
+	defer g.snapshotMu.Unlock()
+	if g.WinnerDeclared || g.snapshotDropped {
+		return nil
+	}
+
+	if err == nil {
+		err = model.SaveSnapshot(g.RoomID, data)
+	}
+	if err != nil {
+		log.Printf("[ERROR][SNAPSHOT] Failed to save room %s: %v", g.RoomID, err)
+	}
+	return err
+}
+
+// dropSnapshot deletes the snapshot of a finished match and keeps it from
+// being written again.
+func (g *Game) dropSnapshot() {
+	g.snapshotMu.Lock()
+	defer g.snapshotMu.Unlock()
+	g.snapshotDropped = true
+	if err := model.DeleteSnapshot(g.RoomID); err != nil {
+		log.Printf("[ERROR][SNAPSHOT] Failed to delete room %s: %v", g.RoomID, err)
+	}
+}
+
+// SuspendMatches pauses every running match and saves it for the next
+// start; matches that cannot be saved are ended as draws instead.
+func SuspendMatches() {
+	for _, g := range liveGames() {
+		unlock := g.lockMatch()
+		if g.WinnerDeclared {
+			unlock()
+			continue
+		}
+		if !g.Paused {
+			g.Paused, g.pausedAt = true, time.Now()
+		}
+		g.StopGameLoop()
+		if g.TurnTimerCancel != nil {
+			g.TurnTimerCancel()
+		}
+		unlock()
+
+		if err := g.saveSnapshot(); err != nil {
+			g.abort(model.EndShutdown, "Match ended: "+shutdownMessage)
+			continue
+		}
+		log.Printf("[INFO][SNAPSHOT] Saved room %s for restart", g.RoomID)
+		g.broadcast(utils.Response{
+			Type:    "match_suspended",
+			Success: true,
+			Message: "The match will continue when the server is back",
+			Data:    map[string]string{"room_id": g.RoomID},
+		})
+	}
+}
+
+// ==== RESTORING ====
+
+// RestoreSnapshots recreates the rooms saved before the last stop. They
+// stay paused until both players reattach.
+func (e *Engine) RestoreSnapshots() int {
+	snapshots, err := model.LoadSnapshots()
+	if err != nil {
+		log.Printf("[ERROR][SNAPSHOT] Failed to read snapshots: %v", err)
+	}
+
+	restored := 0
+	for roomID, data := range snapshots {
+		var s Snapshot
+		err := json.Unmarshal(data, &s)
+		if err == nil && s.Version != SnapshotVersion {
+			err = fmt.Errorf("unsupported snapshot version %d", s.Version)
+		}
+		var room *Room
+		if err == nil {
+			room, err = restoreRoom(s, e.tickRate)
+		}
+		if err != nil {
+			log.Printf("[WARN][SNAPSHOT] Skipping room %s: %v", roomID, err)
+			continue
+		}
+
+		roomsMu.Lock()
+		rooms[room.ID] = room
+		roomsMu.Unlock()
+		RegisterRoom(room.ID, room)
+
+		time.AfterFunc(reattachTimeout, room.expireReattach)
+		log.Printf("[INFO][SNAPSHOT] Restored %s room %s (%s vs %s), saved %s",
+			s.Mode, room.ID, room.Player1.User.Username, room.Player2.User.Username, s.SavedAt.Format(time.RFC3339))
+		restored++
+	}
+	return restored
+}
+
+func restoreRoom(s Snapshot, tickRate time.Duration) (*Room, error) {
+	if len(s.Players) != 2 || (s.Mode != "simple" && s.Mode != "enhanced") {
+		return nil, errors.New("malformed snapshot")
+	}
+
+	now := time.Now()
+	battleSystem := NewBattleSystem(tickRate)
+
+	var players []*model.Player
+	for _, ps := range s.Players {
+		user, ok := model.FindUserByUsername(ps.Username)
+		if !ok {
+			return nil, fmt.Errorf("user %s: %w", ps.Username, model.ErrUserNotFound)
+		}
+
+		p := &model.Player{
+			Mana:          ps.Mana,
+			Towers:        ps.Towers,
+			Troops:        ps.Hand,
+			TroopQueue:    ps.Queue,
+			Active:        true,
+			User:          &user,
+			Matched:       make(chan bool, 1),
+			Turn:          ps.Turn,
+			LastManaRegen: now,
+			Gold:          ps.Gold,
+			Deck:          ps.Deck,
+			Stats:         ps.Stats,
+		}
+
+		// Tower instances share their template with the player's towers
+		for _, ts := range s.Towers {
+			template := p.Towers[ts.Type]
+			if ts.Owner != ps.Username || template == nil {
+				continue
+			}
+			instance := &model.TowerInstance{
+				ID:             ts.ID,
+				Template:       template,
+				TypeEntity:     "tower",
+				Owner:          ts.Owner,
+				Area:           ts.Area,
+				IsDestroyed:    ts.IsDestroyed,
+				LastAttackTime: now,
+			}
+			p.TowerInstances = append(p.TowerInstances, instance)
+			battleSystem.AddEntity(instance)
+		}
+		if p.Towers["king"] == nil || len(p.TowerInstances) == 0 {
+			return nil, fmt.Errorf("towers of %s missing", ps.Username)
+		}
+		players = append(players, p)
+	}
+
+	for _, t := range s.Troops {
+		if t.Template != nil {
+			battleSystem.AddEntity(t)
+		}
+	}
+
+	g := &Game{
+		RoomID:         s.RoomID,
+		Player1:        players[0],
+		Player2:        players[1],
+		Turn:           s.Turn,
+		TurnNumber:     s.TurnNumber,
+		Started:        true,
+		Enhanced:       s.Mode == "enhanced",
+		StartTime:      now.Add(-time.Duration(s.ElapsedMs) * time.Millisecond),
+		BattleSystem:   battleSystem,
+		TickerStopChan: battleSystem.TickerStopChan,
+		Phase:          s.Phase,
+		Rules:          s.Rules,
+		Paused:         true,
+		pausedAt:       now,
+		reattached:     make(map[string]bool),
+		replay: &replayRecorder{
+			start:  now.Add(-time.Duration(s.ReplayMs) * time.Millisecond),
+			events: s.Replay,
+			chat:   s.Chat,
+		},
+	}
+	if g.Enhanced {
+		g.MaxTime = time.Duration(s.Rules.MatchDuration)
+	}
+	if g.Phase == "" {
+		g.Phase = PhaseRegular
+	}
+	if g.Phase != PhaseRegular {
+		g.PhaseStarted = now.Add(-time.Duration(s.PhaseElapsedMs) * time.Millisecond)
+	}
+
+	return &Room{
+		ID:      s.RoomID,
+		Player1: g.Player1,
+		Player2: g.Player2,
+		Game:    g,
+		ChatLog: s.Chat,
+	}, nil
+}
+
+// ReattachPlayer binds a returning player's connection to their restored
+// match and resumes it once both players are back.
+func ReattachPlayer(conn *websocket.Conn, username string) {
+	room := restoredRoomOf(username)
+	if room == nil {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "reattach_response",
+			Success: false,
+			Message: "No match to rejoin",
+		})
+		return
+	}
+
+	g := room.Game
+	player, opponent := g.Player1, g.Player2
+	if player.User.Username != username {
+		player, opponent = g.Player2, g.Player1
+	}
+
+	// The match may have been settled since it was looked up
+	room.mu.Lock()
+	waiting := g.Paused && !g.WinnerDeclared
+	if waiting {
+		g.reattached[username] = true
+	}
+	ready := waiting && len(g.reattached) == 2
+	room.mu.Unlock()
+	if !waiting {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "reattach_response",
+			Success: false,
+			Message: "No match to rejoin",
+		})
+		return
+	}
+	model.RegisterConnection(conn, player)
+
+	log.Printf("[INFO][SNAPSHOT] %s reattached to room %s", username, room.ID)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "reattach_response",
+		Success: true,
+		Message: "Rejoined match",
+		Data: map[string]interface{}{
+			"room_id":  room.ID,
+			"mode":     g.Mode(),
+			"user":     player,
+			"opponent": opponent,
+			"turn":     g.Turn,
+			"map":      g.BattleSystem.GetEntityList(),
+			"rules":    g.Rules,
+			"waiting":  !ready,
+		},
+	})
+
+	if ready {
+		g.resume()
+	}
+}
+
+func restoredRoomOf(username string) *Room {
+	roomsMu.RLock()
+	defer roomsMu.RUnlock()
+	for _, room := range rooms {
+		g := room.Game
+		if g == nil || !g.Paused || g.reattached == nil || g.WinnerDeclared {
+			continue
+		}
+		if room.Player1.User.Username == username || room.Player2.User.Username == username {
+			return room
+		}
+	}
+	return nil
+}
+
+// resume restarts a restored match's clocks where they stopped.
+func (g *Game) resume() {
+	paused := time.Since(g.pausedAt)
+	g.StartTime = g.StartTime.Add(paused)
+	if !g.PhaseStarted.IsZero() {
+		g.PhaseStarted = g.PhaseStarted.Add(paused)
+	}
+	g.Player1.LastManaRegen = time.Now()
+	g.Player2.LastManaRegen = time.Now()
+	g.replay.mu.Lock()
+	g.replay.start = g.replay.start.Add(paused)
+	g.replay.mu.Unlock()
+	g.Paused = false
+
+	if g.Enhanced {
+		go g.startTicker()
+	} else {
+		// The player on turn gets a full turn again
+		g.StartTurnTimer()
+	}
+
+	log.Printf("[INFO][SNAPSHOT] Room %s resumed", g.RoomID)
+	g.broadcast(utils.Response{
+		Type:    "match_resumed",
+		Success: true,
+		Message: "Both players are back, the match continues",
+		Data: map[string]interface{}{
+			"room_id": g.RoomID,
+			"turn":    g.Turn,
+		},
+	})
+}
+
+// expireReattach settles a restored match that not everyone came back to:
+// a player who returned wins, and if nobody did it ends as a draw.
+func (r *Room) expireReattach() {
+	g := r.Game
+
+	// Checked under the room lock ReattachPlayer holds, so a match both
+	// players just came back to is left to resume
+	r.mu.Lock()
+	if !g.Paused || g.WinnerDeclared || len(g.reattached) == 2 {
+		r.mu.Unlock()
+		return
+	}
+	var back *model.Player
+	for _, p := range []*model.Player{g.Player1, g.Player2} {
+		if g.reattached[p.User.Username] {
+			back = p
+		}
+	}
+	g.Paused = false
+	r.mu.Unlock()
+
+	if back == nil {
+		g.abort(model.EndShutdown, "Match ended: players did not return")
+		return
+	}
+	if g.SetWinner(back) {
+		g.broadcast(utils.Response{
+			Type:    "game_over_response",
+			Success: true,
+			Message: back.User.Username + " wins, opponent did not return",
+			Data: map[string]interface{}{
+				"winner": back,
+			},
+		})
+	}
+}
+
+// rejectPaused refuses match actions while a restored match waits for its
+// players.
+func rejectPaused(conn *websocket.Conn, room *Room, responseType string) bool {
+	if room.Game == nil || !room.Game.Paused {
+		return false
+	}
+	utils.WriteMessage(conn, utils.Response{
+		Type:    responseType,
+		Success: false,
+		Message: "Match is paused until both players are back",
+	})
+	return true
+}
@@ -0,0 +1,306 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"royaka/internal/utils"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const maxSpectatorDelay = 10 * time.Second
+
+type spectator struct {
+	Username string
+	Conn     *websocket.Conn
+	Delay    time.Duration
+}
+
+var (
+	// roomID -> username -> spectator
+	spectators   = make(map[string]map[string]*spectator)
+	spectatorsMu sync.RWMutex
+)
+
+func HandleSpectate(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.SpectateRequest
+
+	if err := json.Unmarshal(data, &req); err != nil || req.RoomID == "" {
+		log.Printf("[WARN][SPECTATE] invalid request: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "spectate_response",
+			Success: false,
+			Message: invalidRequestMessage,
+		})
+		return
+	}
+
+	// Only a logged in connection may watch, as itself
+	username := ClientUsername(conn)
+	if username == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "spectate_response",
+			Success: false,
+			Message: "Login required to spectate",
+		})
+		return
+	}
+
+	roomsMu.RLock()
+	room, exists := rooms[req.RoomID]
+	roomsMu.RUnlock()
+	if !exists || room.Game.WinnerDeclared {
+		log.Printf("[WARN][SPECTATE] room %s not found for %s", req.RoomID, username)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "spectate_response",
+			Success: false,
+			Message: roomRequestMessage,
+		})
+		return
+	}
+
+	if room.Player1.User.Username == username || room.Player2.User.Username == username {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "spectate_response",
+			Success: false,
+			Message: "You are playing in this match",
+		})
+		return
+	}
+
+	delay := time.Duration(req.Delay) * time.Second
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxSpectatorDelay {
+		delay = maxSpectatorDelay
+	}
+
+	// A connection watches at most one room at a time
+	RemoveSpectator(conn)
+
+	spectatorsMu.Lock()
+	if spectators[room.ID] == nil {
+		spectators[room.ID] = make(map[string]*spectator)
+	}
+	spectators[room.ID][username] = &spectator{Username: username, Conn: conn, Delay: delay}
+	spectatorsMu.Unlock()
+
+	log.Printf("[INFO][SPECTATE] %s is watching room %s (delay %s)", username, room.ID, delay)
+
+	dataPayload := map[string]interface{}{
+		"room_id":    room.ID,
+		"mode":       room.Game.Mode(),
+		"player1":    room.Player1,
+		"player2":    room.Player2,
+		"delay":      int(delay.Seconds()),
+		"spectators": SpectatorCount(room.ID),
+	}
+	if room.Game.Enhanced {
+		dataPayload["map"] = room.Game.BattleSystem.GetEntityList()
+		dataPayload["time"] = room.Game.MaxTime.Milliseconds()
+	} else {
+		dataPayload["turn"] = room.Game.Turn
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "spectate_response",
+		Success: true,
+		Message: "Spectating match",
+		Data:    dataPayload,
+	})
+
+	room.Game.notifySpectatorCount()
+}
+
+func HandleStopSpectating(conn *websocket.Conn, data json.RawMessage) {
+	roomID := RemoveSpectator(conn)
+	if roomID == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "stop_spectating_response",
+			Success: false,
+			Message: "You are not spectating a match",
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "stop_spectating_response",
+		Success: true,
+		Message: "Stopped spectating",
+	})
+}
+
+func HandleListLiveMatches(conn *websocket.Conn, data json.RawMessage) {
+	roomsMu.RLock()
+	matches := make([]map[string]interface{}, 0, len(rooms))
+	for id, room := range rooms {
+		if room.Game == nil || room.Game.WinnerDeclared {
+			continue
+		}
+		matches = append(matches, map[string]interface{}{
+			"room_id":    id,
+			"mode":       room.Game.Mode(),
+			"player1":    room.Player1.User.Username,
+			"player2":    room.Player2.User.Username,
+			"elapsed":    time.Since(room.Game.StartTime).Milliseconds(),
+			"spectators": SpectatorCount(id),
+		})
+	}
+	roomsMu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i]["room_id"].(string) < matches[j]["room_id"].(string)
+	})
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "live_matches_response",
+		Success: true,
+		Message: "Live matches loaded",
+		Data:    matches,
+	})
+}
+
+// RemoveSpectator detaches the connection from the room it is watching and
+// returns that room's ID, or "" if it was not spectating.
+func RemoveSpectator(conn *websocket.Conn) string {
+	spectatorsMu.Lock()
+	roomID := ""
+	for id, viewers := range spectators {
+		for username, s := range viewers {
+			if s.Conn == conn {
+				delete(viewers, username)
+				roomID = id
+			}
+		}
+		if len(viewers) == 0 {
+			delete(spectators, id)
+		}
+	}
+	spectatorsMu.Unlock()
+
+	if roomID == "" {
+		return ""
+	}
+
+	roomsMu.RLock()
+	room, exists := rooms[roomID]
+	roomsMu.RUnlock()
+	if exists {
+		room.Game.notifySpectatorCount()
+	}
+	return roomID
+}
+
+// IsSpectator reports whether the connection is watching a match.
+func IsSpectator(conn *websocket.Conn) bool {
+	spectatorsMu.RLock()
+	defer spectatorsMu.RUnlock()
+
+	for _, viewers := range spectators {
+		for _, s := range viewers {
+			if s.Conn == conn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isWatching(roomID string, s *spectator) bool {
+	spectatorsMu.RLock()
+	defer spectatorsMu.RUnlock()
+	return spectators[roomID][s.Username] == s
+}
+
+func SpectatorCount(roomID string) int {
+	spectatorsMu.RLock()
+	defer spectatorsMu.RUnlock()
+	return len(spectators[roomID])
+}
+
+func clearSpectators(roomID string) {
+	spectatorsMu.Lock()
+	delete(spectators, roomID)
+	spectatorsMu.Unlock()
+}
+
+// rejectSpectator answers an action request coming from a spectating
+// connection and reports whether the caller should stop processing it.
+func rejectSpectator(conn *websocket.Conn, responseType string) bool {
+	if !IsSpectator(conn) {
+		return false
+	}
+	log.Printf("[WARN][SPECTATE] spectator attempted %s", responseType)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    responseType,
+		Success: false,
+		Message: "Spectators cannot take actions",
+	})
+	return true
+}
+
+// sendToSpectators relays a room broadcast to its viewers. Payloads for
+// delayed viewers are copied up front so they show the state at send time.
+func sendToSpectators(roomID string, payload utils.Response) {
+	spectatorsMu.RLock()
+	viewers := make([]*spectator, 0, len(spectators[roomID]))
+	for _, s := range spectators[roomID] {
+		viewers = append(viewers, s)
+	}
+	spectatorsMu.RUnlock()
+
+	if len(viewers) == 0 {
+		return
+	}
+
+	var frozen *utils.Response
+	for _, s := range viewers {
+		if s.Delay == 0 {
+			sendToClient(s.Username, payload)
+			continue
+		}
+
+		if frozen == nil {
+			frozen = freezePayload(payload)
+		}
+		viewer, delayed := s, *frozen
+		time.AfterFunc(s.Delay, func() {
+			if isWatching(roomID, viewer) {
+				sendToClient(viewer.Username, delayed)
+			}
+		})
+	}
+}
+
+func freezePayload(payload utils.Response) *utils.Response {
+	frozen := payload
+	raw, err := json.Marshal(payload.Data)
+	if err != nil {
+		log.Printf("[ERROR][SPECTATE] Failed to copy payload %s: %v", payload.Type, err)
+		return &frozen
+	}
+
+	var data any
+	if err := json.Unmarshal(raw, &data); err == nil {
+		frozen.Data = data
+	}
+	return &frozen
+}
+
+// notifySpectatorCount tells both players how many viewers are watching.
+func (g *Game) notifySpectatorCount() {
+	payload := utils.Response{
+		Type:    "spectator_update",
+		Success: true,
+		Message: "Spectator count updated",
+		Data: map[string]interface{}{
+			"spectators": SpectatorCount(g.RoomID),
+		},
+	}
+	sendToClient(g.Player1.User.Username, payload)
+	sendToClient(g.Player2.User.Username, payload)
+}
@@ -0,0 +1,575 @@
+// internal/game/turn.go
+
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"royaka/internal/model"
+	"royaka/internal/utils"
+)
+
+// Simple mode turn states. A match waits while it is paused, the player on
+// turn acts, their action resolves, and the match goes back to acting or
+// is over:
+//
+//	waiting -> acting -> resolving -> acting | game_over
+const (
+	TurnWaiting   = "waiting"
+	TurnActing    = "acting"
+	TurnResolving = "resolving"
+	TurnGameOver  = "game_over"
+)
+
+// Actions a player can take on their turn; each one ends it. A timeout is
+// the turn timer skipping the turn for them.
+const (
+	ActionAttack  = "attack"
+	ActionHeal    = "heal"
+	ActionBoost   = "boost"   // a troop's next attack deals more damage
+	ActionFortify = "fortify" // an own tower has more DEF until the next turn
+	ActionSwap    = "swap"    // a card in hand goes back for the next in reserve
+	ActionSkip    = "skip"
+	ActionTimeout = "timeout"
+)
+
+var (
+	errNotTurnBased   = errors.New("match is not turn based")
+	errMatchOver      = errors.New("match is over")
+	errMatchPaused    = errors.New("match is paused")
+	errNotYourTurn    = errors.New("not your turn")
+	errTurnPassed     = errors.New("turn has already passed")
+	errUnknownAction  = errors.New("unknown action")
+	errTroopNotInHand = errors.New("troop is not in hand")
+	errNotEnoughMana  = errors.New("not enough mana")
+	errInvalidTarget  = errors.New("invalid tower target")
+	errTowerDestroyed = errors.New("tower is already destroyed")
+	errKingProtected  = errors.New("guard towers still stand")
+	errNotHealer      = errors.New("troop cannot heal")
+	errNothingToHeal  = errors.New("no tower to heal")
+	errAlreadyBoosted = errors.New("troop is already boosted")
+	errFortified      = errors.New("tower is already fortified")
+	errReserveEmpty   = errors.New("no cards in reserve")
+)
+
+// targetTowers are the towers an attack can aim at, in order.
+var targetTowers = []string{"guard1", "guard2", "king"}
+
+// TurnAction is what a player asks to do on their turn.
+type TurnAction struct {
+	Kind   string
+	Troop  string
+	Target string // tower to attack or fortify
+}
+
+// LegalAction is an action the player on turn may take now, with its mana
+// cost. Attacks and fortifies list every tower they may target.
+type LegalAction struct {
+	Action  string   `json:"action"`
+	Troop   string   `json:"troop,omitempty"`
+	Mana    int      `json:"mana,omitempty"`
+	Targets []string `json:"targets,omitempty"`
+}
+
+// TurnResult describes one resolved turn. It is broadcast as turn_result.
+type TurnResult struct {
+	Number    int    `json:"number"` // the turn played, from 1
+	Player    string `json:"player"`
+	Action    string `json:"action"`
+	Troop     string `json:"troop,omitempty"`
+	Target    string `json:"target,omitempty"` // tower attacked, healed or fortified
+	Amount    int    `json:"amount,omitempty"` // damage dealt, HP healed or DEF added
+	Crit      bool   `json:"crit,omitempty"`
+	Boosted   bool   `json:"boosted,omitempty"` // the attack used a boost
+	Destroyed bool   `json:"destroyed,omitempty"`
+	Drawn     string `json:"drawn,omitempty"` // card swapped into the hand
+	ManaSpent int    `json:"mana_spent,omitempty"`
+	Message   string `json:"message"`
+
+	// The match after the turn
+	State        string                        `json:"state"`
+	Next         string                        `json:"next,omitempty"` // player on turn
+	ExtraTurn    bool                          `json:"extra_turn,omitempty"`
+	Deadline     time.Time                     `json:"deadline,omitzero"`
+	LegalActions []LegalAction                 `json:"legal_actions,omitempty"` // for Next
+	Mana         map[string]int                `json:"mana"`
+	Towers       map[string]map[string]float64 `json:"towers"` // username -> tower -> HP
+	Winner       string                        `json:"winner,omitempty"`
+
+	winner  *model.Player
+	outcome string
+}
+
+// TurnState returns where a simple mode match is in its turn cycle.
+func (g *Game) TurnState() string {
+	switch {
+	case g.WinnerDeclared:
+		return TurnGameOver
+	case g.Paused:
+		return TurnWaiting
+	case g.resolving:
+		return TurnResolving
+	default:
+		return TurnActing
+	}
+}
+
+// Act validates action against the current turn and resolves it. Nothing
+// changes when it returns an error.
+func (g *Game) Act(player *model.Player, action TurnAction) (*TurnResult, error) {
+	if action.Kind == ActionTimeout {
+		return nil, errUnknownAction
+	}
+	return g.act(player, 0, action)
+}
+
+// act resolves action for player, or for the player on turn when player is
+// nil. A non-zero number only lets it act on that turn.
+func (g *Game) act(player *model.Player, number int, action TurnAction) (*TurnResult, error) {
+	if g.Enhanced {
+		return nil, errNotTurnBased
+	}
+
+	g.turnMu.Lock()
+	defer g.turnMu.Unlock()
+
+	if number != 0 && number != g.TurnNumber {
+		return nil, errTurnPassed
+	}
+	if player == nil {
+		player = g.CurrentPlayer()
+	}
+	if err := g.checkActing(player); err != nil {
+		return nil, err
+	}
+
+	var troop *model.Troop
+	var cost int
+	if action.Kind != ActionSkip && action.Kind != ActionTimeout {
+		var err error
+		if troop, cost, err = g.checkAction(player, action); err != nil {
+			return nil, err
+		}
+	}
+
+	g.resolving = true
+	player.Mana -= cost
+	result := &TurnResult{
+		Number:    g.TurnNumber,
+		Player:    player.User.Username,
+		Action:    action.Kind,
+		ManaSpent: cost,
+	}
+	if troop != nil {
+		result.Troop = troop.Name
+	}
+	switch action.Kind {
+	case ActionAttack:
+		g.resolveAttack(player, troop, action.Target, result)
+	case ActionHeal:
+		g.resolveHeal(player, troop, result)
+	case ActionBoost:
+		troop.BoostAttack()
+		result.Message = fmt.Sprintf("%s is boosted for its next attack", troop.Name)
+	case ActionFortify:
+		tower := player.Towers[action.Target]
+		tower.Fortify()
+		result.Target, result.Amount = action.Target, int(tower.FortifiedDEF)
+		result.Message = fmt.Sprintf("%s tower is fortified until your next turn", tower.Type)
+	case ActionSwap:
+		drawn := player.TroopQueue[0]
+		troop.EndBoost()
+		player.RotateTroop(troop.Name)
+		result.Drawn = drawn.Name
+		result.Message = fmt.Sprintf("%s swapped for %s", troop.Name, drawn.Name)
+	case ActionSkip:
+		result.Message = "Turn skipped"
+	case ActionTimeout:
+		result.Message = "Turn skipped due to timeout"
+	}
+	player.Turn++
+
+	// Destroying a tower keeps the turn, anything else passes it, unless
+	// the king fell
+	if g.Opponent(player).Towers["king"].HP <= 0 {
+		result.winner, result.outcome = g.CheckWinner()
+	} else if result.Destroyed {
+		result.ExtraTurn = true
+		player.Mana = min(player.Mana+g.Rules.ManaPerTurn, g.Rules.MaxMana)
+		g.TurnNumber++
+		g.StartTurnTimer()
+	} else {
+		g.TurnNumber++
+		g.SwitchTurn()
+	}
+	g.resolving = false
+
+	g.describeTurn(result)
+	return result, nil
+}
+
+func (g *Game) checkActing(player *model.Player) error {
+	switch g.TurnState() {
+	case TurnGameOver:
+		return errMatchOver
+	case TurnWaiting:
+		return errMatchPaused
+	}
+	if g.Turn != player.User.Username {
+		return errNotYourTurn
+	}
+	return nil
+}
+
+// checkAction checks that the player may take action now and returns the
+// troop it uses, if any, and its mana cost.
+func (g *Game) checkAction(player *model.Player, action TurnAction) (*model.Troop, int, error) {
+	var troop *model.Troop
+	if action.Kind != ActionFortify {
+		for _, t := range player.Troops {
+			if t.Name == action.Troop {
+				troop = t
+				break
+			}
+		}
+		if troop == nil {
+			return nil, 0, errTroopNotInHand
+		}
+	}
+
+	var cost int
+	var err error
+	switch action.Kind {
+	case ActionAttack:
+		cost, err = troop.MANA, g.checkTarget(player, action.Target)
+	case ActionHeal:
+		cost = troop.MANA
+		if troop.Type != "healer" {
+			err = errNotHealer
+		} else if healTarget(player) == nil {
+			err = errNothingToHeal
+		}
+	case ActionBoost:
+		cost = g.Rules.BoostCost
+		if troop.Boosted {
+			err = errAlreadyBoosted
+		}
+	case ActionFortify:
+		cost, err = g.Rules.FortifyCost, checkFortify(player, action.Target)
+	case ActionSwap:
+		cost = g.Rules.SwapCost
+		if len(player.TroopQueue) == 0 {
+			err = errReserveEmpty
+		}
+	default:
+		err = errUnknownAction
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if player.Mana < cost {
+		return nil, 0, errNotEnoughMana
+	}
+	return troop, cost, nil
+}
+
+// checkTarget checks that the opponent's tower can be attacked: it stands,
+// and for the king, both guards are down.
+func (g *Game) checkTarget(player *model.Player, target string) error {
+	towers := g.Opponent(player).Towers
+	tower, ok := towers[target]
+	if !ok || tower == nil {
+		return errInvalidTarget
+	}
+	if tower.HP <= 0 {
+		return errTowerDestroyed
+	}
+	if target == "king" && (towers["guard1"].HP > 0 || towers["guard2"].HP > 0) {
+		return errKingProtected
+	}
+	return nil
+}
+
+// checkFortify checks that the player's own tower can be fortified: it
+// stands and is not fortified yet.
+func checkFortify(player *model.Player, target string) error {
+	tower, ok := player.Towers[target]
+	if !ok || tower == nil {
+		return errInvalidTarget
+	}
+	if tower.HP <= 0 {
+		return errTowerDestroyed
+	}
+	if tower.Fortified() {
+		return errFortified
+	}
+	return nil
+}
+
+func (g *Game) resolveAttack(player *model.Player, troop *model.Troop, target string, result *TurnResult) {
+	tower := g.Opponent(player).Towers[target]
+	damage, isCrit, destroyed := g.AttackTower(player, troop, tower)
+	result.Boosted = troop.Boosted
+	troop.EndBoost()
+
+	player.Stats.CardsPlayed++
+	if destroyed {
+		if target == "king" {
+			player.Stats.KingTowers++
+		} else {
+			player.Stats.GuardTowers++
+		}
+	}
+
+	result.Target = target
+	result.Amount, result.Crit, result.Destroyed = int(damage), isCrit, destroyed
+
+	result.Message = fmt.Sprintf("%s dealt %d damage to %s", troop.Name, int(damage), tower.Type)
+	if result.Boosted {
+		result.Message += " (Boosted!)"
+	}
+	if isCrit {
+		result.Message += " (Critical hit!)"
+	}
+	if destroyed {
+		result.Message += " and destroyed it!"
+	}
+}
+
+func (g *Game) resolveHeal(player *model.Player, troop *model.Troop, result *TurnResult) {
+	tower := healTarget(player)
+	before := tower.HP
+	amount, isCrit := troop.CalculateHeal(player.User.Level)
+	tower.HP = min(tower.HP+float64(amount), tower.MaxHP)
+
+	player.Stats.CardsPlayed++
+	player.Stats.Heals++
+
+	result.Target = tower.Type
+	result.Amount, result.Crit = int(tower.HP-before), isCrit
+
+	result.Message = fmt.Sprintf("%s healed %s tower for %d HP", troop.Name, tower.Type, result.Amount)
+	if isCrit {
+		result.Message += " (Critical heal!)"
+	}
+}
+
+// healTarget returns the player's standing, damaged tower with the least
+// HP, or nil if none is damaged.
+func healTarget(player *model.Player) *model.Tower {
+	var target *model.Tower
+	for _, tower := range player.Towers {
+		if tower.HP <= 0 || tower.HP >= tower.MaxHP {
+			continue
+		}
+		if target == nil || tower.HP < target.HP {
+			target = tower
+		}
+	}
+	return target
+}
+
+// describeTurn fills in the state of the match after the turn.
+func (g *Game) describeTurn(result *TurnResult) {
+	result.State = g.TurnState()
+	result.Mana = make(map[string]int, 2)
+	result.Towers = make(map[string]map[string]float64, 2)
+	for _, p := range []*model.Player{g.Player1, g.Player2} {
+		result.Mana[p.User.Username] = p.Mana
+		hp := make(map[string]float64, len(p.Towers))
+		for name, tower := range p.Towers {
+			hp[name] = tower.HP
+		}
+		result.Towers[p.User.Username] = hp
+	}
+
+	if result.winner != nil {
+		result.Winner = result.winner.User.Username
+	}
+	if result.State == TurnActing {
+		result.Next = g.Turn
+		result.Deadline = g.turnDeadline
+		result.LegalActions = g.legalActions(g.CurrentPlayer())
+	}
+}
+
+// LegalActions lists what player may do now; nothing unless it is their
+// turn in a running simple mode match.
+func (g *Game) LegalActions(player *model.Player) []LegalAction {
+	if g.Enhanced {
+		return nil
+	}
+	g.turnMu.Lock()
+	defer g.turnMu.Unlock()
+	if g.checkActing(player) != nil {
+		return nil
+	}
+	return g.legalActions(player)
+}
+
+func (g *Game) legalActions(player *model.Player) []LegalAction {
+	actions := []LegalAction{{Action: ActionSkip}}
+	for _, troop := range player.Troops {
+		if troop.MANA <= player.Mana {
+			var targets []string
+			for _, target := range targetTowers {
+				if g.checkTarget(player, target) == nil {
+					targets = append(targets, target)
+				}
+			}
+			if len(targets) > 0 {
+				actions = append(actions, LegalAction{Action: ActionAttack, Troop: troop.Name, Mana: troop.MANA, Targets: targets})
+			}
+			if troop.Type == "healer" && healTarget(player) != nil {
+				actions = append(actions, LegalAction{Action: ActionHeal, Troop: troop.Name, Mana: troop.MANA})
+			}
+		}
+		if !troop.Boosted && g.Rules.BoostCost <= player.Mana {
+			actions = append(actions, LegalAction{Action: ActionBoost, Troop: troop.Name, Mana: g.Rules.BoostCost})
+		}
+		if len(player.TroopQueue) > 0 && g.Rules.SwapCost <= player.Mana {
+			actions = append(actions, LegalAction{Action: ActionSwap, Troop: troop.Name, Mana: g.Rules.SwapCost})
+		}
+	}
+
+	if g.Rules.FortifyCost <= player.Mana {
+		var targets []string
+		for _, target := range targetTowers {
+			if checkFortify(player, target) == nil {
+				targets = append(targets, target)
+			}
+		}
+		if len(targets) > 0 {
+			actions = append(actions, LegalAction{Action: ActionFortify, Mana: g.Rules.FortifyCost, Targets: targets})
+		}
+	}
+	return actions
+}
+
+// broadcastTurn sends the turn's result, and the end of the match if it
+// ended it.
+func (g *Game) broadcastTurn(result *TurnResult) {
+	g.broadcast(utils.Response{
+		Type:    "turn_result",
+		Success: true,
+		Message: result.Message,
+		Data:    result,
+	})
+
+	if result.winner != nil {
+		g.broadcast(utils.Response{
+			Type:    "game_over_response",
+			Success: true,
+			Message: result.outcome,
+			Data: map[string]interface{}{
+				"winner": result.winner,
+			},
+		})
+	}
+}
+
+// turnErrorMessages are the messages shown to players for Act errors.
+var turnErrorMessages = map[error]string{
+	errNotTurnBased:   "This match is not turn based",
+	errMatchOver:      "The match is over",
+	errMatchPaused:    "Match is paused until both players are back",
+	errNotYourTurn:    "It's not your turn!",
+	errTurnPassed:     "It's not your turn!",
+	errUnknownAction:  invalidRequestMessage,
+	errTroopNotInHand: "Troop not in hand",
+	errNotEnoughMana:  manaRequestMessage,
+	errInvalidTarget:  "Invalid tower target",
+	errTowerDestroyed: "That tower is already destroyed",
+	errKingProtected:  "You must destroy both guard towers before attacking the king!",
+	errNotHealer:      "Only healing troop can heal towers",
+	errNothingToHeal:  "No damaged tower to heal",
+	errAlreadyBoosted: "That troop is already boosted",
+	errFortified:      "That tower is already fortified",
+	errReserveEmpty:   "No cards left in your reserve",
+}
+
+func turnErrorMessage(err error) string {
+	if message, ok := turnErrorMessages[err]; ok {
+		return message
+	}
+	return invalidRequestMessage
+}
+
+// ===================== Turn Management =====================
+
+// SwitchTurn passes the turn to the other player, who gets mana for it
+// from their second turn on. Their fortified towers go back to normal.
+func (g *Game) SwitchTurn() {
+	if g.Turn == g.Player1.User.Username {
+		g.Turn = g.Player2.User.Username
+	} else {
+		g.Turn = g.Player1.User.Username
+	}
+
+	g.LastTick = time.Now()
+	g.StartTurnTimer()
+
+	nextPlayer := g.CurrentPlayer()
+	if nextPlayer.Turn > 0 {
+		nextPlayer.Mana = min(nextPlayer.Mana+g.Rules.ManaPerTurn, g.Rules.MaxMana)
+	}
+	for _, tower := range nextPlayer.Towers {
+		tower.EndFortify()
+	}
+}
+
+// StartTurnTimer gives the current turn its full time, cancelling the
+// previous turn's timer. When it runs out the turn is skipped.
+func (g *Game) StartTurnTimer() {
+	if g.TurnTimerCancel != nil {
+		g.TurnTimerCancel()
+	}
+
+	turnTime := time.Duration(g.Rules.TurnTime)
+	timer := time.NewTimer(turnTime)
+	cancelChan := make(chan struct{})
+	g.turnDeadline = time.Now().Add(turnTime)
+
+	// Leaving, shutdown and the next turn may all cancel the same timer
+	var cancelOnce sync.Once
+	g.TurnTimerCancel = func() {
+		cancelOnce.Do(func() {
+			timer.Stop()
+			close(cancelChan)
+		})
+	}
+
+	go func(number int) {
+		select {
+		case <-timer.C:
+			g.timeoutTurn(number)
+		case <-cancelChan:
+		}
+	}(g.TurnNumber)
+}
+
+// timeoutTurn skips turn number if it is still being played.
+func (g *Game) timeoutTurn(number int) {
+	result, err := g.act(nil, number, TurnAction{Kind: ActionTimeout})
+	if err != nil {
+		return
+	}
+	log.Printf("[INFO][TURN] %s timed out", result.Player)
+
+	g.broadcast(utils.Response{
+		Type:    "skip_turn_response",
+		Success: true,
+		Message: result.Message,
+		Data: map[string]interface{}{
+			"turn":    g.Turn,
+			"player1": g.Player1,
+			"player2": g.Player2,
+		},
+	})
+	g.broadcastTurn(result)
+}
@@ -0,0 +1,222 @@
+// internal/model/account.go
+
+package model
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsernameCooldown is how long a user must wait between username changes.
+const UsernameCooldown = 30 * 24 * time.Hour
+
+// DeletedUserName replaces a deleted user's name in match history, replays
+// and reports. It cannot be registered.
+const DeletedUserName = "[deleted]"
+
+var (
+	ErrRenameCooldown = errors.New("username was changed recently")
+	ErrAvatarLocked   = errors.New("avatar is not unlocked")
+)
+
+// ==== AVATARS ====
+
+// randomAvatars is how many avatars new users are picked from, IDs 1 to 15.
+const randomAvatars = 15
+
+// AvatarUnlock is an avatar beyond the starting ones and how to earn it.
+type AvatarUnlock struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+
+	unlocked func(u *User) bool
+}
+
+var avatarUnlocks = []AvatarUnlock{
+	{ID: "16", Description: "Reach level 10",
+		unlocked: func(u *User) bool { return u.Level >= 10 }},
+	{ID: "17", Description: "Reach 2000 trophies",
+		unlocked: func(u *User) bool { return u.BestTrophies >= 2000 }},
+	{ID: "18", Description: "Earn the first Conqueror tier",
+		unlocked: func(u *User) bool { return u.Achievements["conqueror"] >= 1 }},
+}
+
+// AvatarOption is an avatar the user can see, with whether they may use it.
+type AvatarOption struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	Unlocked    bool   `json:"unlocked"`
+}
+
+// AvatarOptions lists every avatar: the starting ones, always unlocked,
+// then the unlockable ones.
+func (u *User) AvatarOptions() []AvatarOption {
+	options := make([]AvatarOption, 0, randomAvatars+len(avatarUnlocks))
+	for id := 1; id <= randomAvatars; id++ {
+		options = append(options, AvatarOption{ID: strconv.Itoa(id), Unlocked: true})
+	}
+	for _, a := range avatarUnlocks {
+		options = append(options, AvatarOption{ID: a.ID, Description: a.Description, Unlocked: a.unlocked(u)})
+	}
+	return options
+}
+
+// SetAvatar changes the user's avatar to one they have unlocked.
+func SetAvatar(username, avatar string) (User, error) {
+	return UpdateUser(username, func(u *User) error {
+		options := u.AvatarOptions()
+		i := slices.IndexFunc(options, func(o AvatarOption) bool { return o.ID == avatar })
+		if i < 0 || !options[i].Unlocked {
+			return ErrAvatarLocked
+		}
+		u.Avatar = avatar
+		return nil
+	})
+}
+
+// ==== RENAME ====
+
+// RenameUser changes a username everywhere it is stored: the user, other
+// users' friend lists, match history, replays and reports. Names are unique without
+// regard to case; a user may change the case of their own name.
+func RenameUser(oldName, newName string) (User, error) {
+	return renameUser(oldName, newName, func(u *User) error {
+		if time.Since(u.RenamedAt) < UsernameCooldown {
+			return ErrRenameCooldown
+		}
+		u.RenamedAt = time.Now()
+		return nil
+	})
+}
+
+// renameUser renames oldName after fn accepts and updates the user, then
+// follows the rename in replays, match history and reports. The returned user has
+// a username whenever the account itself was renamed.
+func renameUser(oldName, newName string, fn func(u *User) error) (User, error) {
+	var renamed User
+	err := ModifyUsers(func(byName map[string]*User) error {
+		u, ok := byName[oldName]
+		if !ok {
+			return ErrUserNotFound
+		}
+		for name := range byName {
+			if name != oldName && strings.EqualFold(name, newName) {
+				return ErrUserExists
+			}
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+
+		u.Username = newName
+		for _, other := range byName {
+			other.Friends = renameIn(other.Friends, oldName, newName)
+			other.FriendRequests = renameIn(other.FriendRequests, oldName, newName)
+		}
+		renamed = *u
+		return nil
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := renameInReplays(oldName, newName); err != nil {
+		return renamed, err
+	}
+	if err := renameInMatches(oldName, newName); err != nil {
+		return renamed, err
+	}
+	return renamed, renameInReports(oldName, newName)
+}
+
+// RecordLogin sets the user's last login to now.
+func RecordLogin(username string) error {
+	_, err := UpdateUser(username, func(u *User) error {
+		u.LastLogin = time.Now()
+		return nil
+	})
+	return err
+}
+
+// NextRename returns when the user may change their name again.
+func (u *User) NextRename() time.Time {
+	if u.RenamedAt.IsZero() {
+		return time.Time{}
+	}
+	return u.RenamedAt.Add(UsernameCooldown)
+}
+
+// ==== DELETE ====
+
+// DeleteUser removes a user and their friendships, and replaces their name
+// with DeletedUserName in match history, replays and reports.
+func DeleteUser(username string) error {
+	usersStorageLock.Lock()
+	users, err := loadUsers()
+	if err == nil {
+		i := slices.IndexFunc(users, func(u User) bool { return u.Username == username })
+		if i < 0 {
+			err = ErrUserNotFound
+		} else {
+			users = slices.Delete(users, i, i+1)
+			for i := range users {
+				users[i].Friends = removeUsername(users[i].Friends, username)
+				users[i].FriendRequests = removeUsername(users[i].FriendRequests, username)
+			}
+			err = saveUsers(users)
+		}
+	}
+	usersStorageLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := renameInReplays(username, DeletedUserName); err != nil {
+		return err
+	}
+	if err := renameInMatches(username, DeletedUserName); err != nil {
+		return err
+	}
+	return renameInReports(username, DeletedUserName)
+}
+
+func renameIn(names []string, oldName, newName string) []string {
+	for i, name := range names {
+		if name == oldName {
+			names[i] = newName
+		}
+	}
+	return names
+}
+
+func removeUsername(names []string, username string) []string {
+	return slices.DeleteFunc(names, func(name string) bool { return name == username })
+}
+
+func renameInReports(oldName, newName string) error {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+
+	reports, err := loadReports()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range reports {
+		if reports[i].Reporter == oldName {
+			reports[i].Reporter = newName
+			changed = true
+		}
+		if reports[i].Reported == oldName {
+			reports[i].Reported = newName
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveReports(reports)
+}
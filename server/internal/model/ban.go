@@ -0,0 +1,93 @@
+// internal/model/ban.go
+
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Kinds of account restriction.
+const (
+	BanKindBan        = "ban"        // no login and no matches
+	BanKindSuspension = "suspension" // may log in but not play matches
+)
+
+var (
+	ErrBanned    = errors.New("account is banned")
+	ErrSuspended = errors.New("account is suspended")
+)
+
+// Ban restricts an account until it expires. A deactivated account
+// (IsActive false) counts as permanently banned.
+type Ban struct {
+	Kind   string    `json:"kind,omitempty"` // empty on older records, meaning ban
+	Reason string    `json:"reason"`
+	By     string    `json:"by"`
+	At     time.Time `json:"at"`
+	Until  time.Time `json:"until,omitzero"` // zero for a permanent ban
+}
+
+func (b *Ban) Active(now time.Time) bool {
+	return b != nil && (b.Until.IsZero() || now.Before(b.Until))
+}
+
+func (b *Ban) IsSuspension() bool {
+	return b != nil && b.Kind == BanKindSuspension
+}
+
+// Banned reports whether the user may not log in.
+func (u *User) Banned() bool {
+	return !u.IsActive || (u.Ban.Active(time.Now()) && !u.Ban.IsSuspension())
+}
+
+// PlayRestriction returns ErrBanned or ErrSuspended when the user may not
+// join matches, nil otherwise.
+func (u *User) PlayRestriction() error {
+	switch {
+	case u.Banned():
+		return ErrBanned
+	case u.Ban.Active(time.Now()):
+		return ErrSuspended
+	}
+	return nil
+}
+
+// BanUser bans a user for duration, or for good when duration is 0.
+// A permanent ban also deactivates the account.
+func BanUser(username, reason, by string, duration time.Duration) (Ban, error) {
+	return restrictUser(username, BanKindBan, reason, by, duration)
+}
+
+// SuspendUser keeps a user out of matches for duration.
+func SuspendUser(username, reason, by string, duration time.Duration) (Ban, error) {
+	if duration <= 0 {
+		return Ban{}, errors.New("suspension needs a duration")
+	}
+	return restrictUser(username, BanKindSuspension, reason, by, duration)
+}
+
+func restrictUser(username, kind, reason, by string, duration time.Duration) (Ban, error) {
+	now := time.Now()
+	ban := Ban{Kind: kind, Reason: reason, By: by, At: now}
+	if duration > 0 {
+		ban.Until = now.Add(duration)
+	}
+
+	_, err := UpdateUser(username, func(u *User) error {
+		u.Ban = &ban
+		u.IsActive = kind != BanKindBan || duration > 0
+		return nil
+	})
+	return ban, err
+}
+
+// UnbanUser lifts any ban or suspension and reactivates the account.
+func UnbanUser(username string) error {
+	_, err := UpdateUser(username, func(u *User) error {
+		u.Ban = nil
+		u.IsActive = true
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,221 @@
+// internal/model/chest.go
+
+package model
+
+import (
+	"errors"
+	"royaka/internal/utils"
+	"time"
+)
+
+const MaxChests = 4
+
+var (
+	ErrChestNotFound = errors.New("chest not found")
+	ErrChestLocked   = errors.New("chest is still locked")
+)
+
+type chestType struct {
+	Name    string
+	Drop    int // relative chance of dropping after a win
+	Unlock  time.Duration
+	MinGold int
+	MaxGold int
+	Cards   int
+	Weights map[string]int // card rarity -> relative weight
+}
+
+var chestTypes = []chestType{
+	{Name: "silver", Drop: 70, Unlock: 15 * time.Minute, MinGold: 20, MaxGold: 50, Cards: 2,
+		Weights: map[string]int{"common": 80, "rare": 18, "epic": 2}},
+	{Name: "gold", Drop: 25, Unlock: time.Hour, MinGold: 60, MaxGold: 120, Cards: 4,
+		Weights: map[string]int{"common": 65, "rare": 30, "epic": 5}},
+	{Name: "magical", Drop: 5, Unlock: 3 * time.Hour, MinGold: 150, MaxGold: 250, Cards: 6,
+		Weights: map[string]int{"common": 45, "rare": 40, "epic": 15}},
+}
+
+// ==== STRUCTS ====
+
+type Chest struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	EarnedAt time.Time `json:"earnedAt"`
+	UnlockAt time.Time `json:"unlockAt"`
+}
+
+type ChestReward struct {
+	Chest Chest       `json:"chest"`
+	Gold  int         `json:"gold"`
+	Cards []CardGrant `json:"cards"`
+}
+
+func (c Chest) Unlocked(now time.Time) bool {
+	return !now.Before(c.UnlockAt)
+}
+
+// ==== CHESTS ====
+
+// AwardChest gives the user a random chest that starts unlocking right away.
+// It returns false when every chest slot is already full.
+func AwardChest(username string) (Chest, bool, error) {
+	var chest Chest
+	awarded := false
+
+	_, err := UpdateUser(username, func(u *User) error {
+		if len(u.Chests) >= MaxChests {
+			return nil
+		}
+
+		ct := rollChestType()
+		now := time.Now()
+		chest = Chest{
+			ID:       generateID(),
+			Type:     ct.Name,
+			EarnedAt: now,
+			UnlockAt: now.Add(ct.Unlock),
+		}
+		u.Chests = append(u.Chests, chest)
+		awarded = true
+		return nil
+	})
+	return chest, awarded, err
+}
+
+// OpenChest grants the rewards of an unlocked chest and removes it, as a
+// single atomic update of the user store.
+func OpenChest(username, chestID string) (User, ChestReward, error) {
+	templates, err := LoadTroop()
+	if err != nil {
+		return User{}, ChestReward{}, err
+	}
+
+	var reward ChestReward
+	user, err := UpdateUser(username, func(u *User) error {
+		idx := -1
+		for i, c := range u.Chests {
+			if c.ID == chestID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return ErrChestNotFound
+		}
+
+		chest := u.Chests[idx]
+		if !chest.Unlocked(time.Now()) {
+			return ErrChestLocked
+		}
+
+		reward = rollChestReward(chest, templates)
+		u.UnlockCards(templates)
+		u.Gold += reward.Gold
+		for i, card := range reward.Cards {
+			// grantCard pays out maxed cards itself
+			reward.Cards[i] = u.grantCard(card.Card, rarityOf(templates, card.Card))
+		}
+		u.Chests = append(u.Chests[:idx], u.Chests[idx+1:]...)
+		return nil
+	})
+	if err != nil {
+		return User{}, ChestReward{}, err
+	}
+
+	cards := make(map[string]int, len(reward.Cards))
+	gold := reward.Gold
+	for _, c := range reward.Cards {
+		cards[c.Card] = c.Level
+		gold += c.Gold
+	}
+	RecordLedger(LedgerEntry{
+		Username: username,
+		Action:   "chest_open",
+		Ref:      reward.Chest.Type + ":" + chestID,
+		Gold:     gold,
+		Cards:    cards,
+		Balance:  user.Gold,
+	})
+	return user, reward, nil
+}
+
+func rollChestType() chestType {
+	weights := make(map[string]int, len(chestTypes))
+	for _, ct := range chestTypes {
+		weights[ct.Name] = ct.Drop
+	}
+	name := weightedPick(weights)
+	for _, ct := range chestTypes {
+		if ct.Name == name {
+			return ct
+		}
+	}
+	return chestTypes[0]
+}
+
+// rollChestReward draws gold and cards for a chest; the cards are returned
+// as names only and granted by the caller.
+func rollChestReward(chest Chest, templates []Troop) ChestReward {
+	ct := chestTypes[0]
+	for _, t := range chestTypes {
+		if t.Name == chest.Type {
+			ct = t
+		}
+	}
+
+	reward := ChestReward{Chest: chest, Gold: ct.MinGold}
+	if n, err := utils.CryptoRandInt(int64(ct.MaxGold - ct.MinGold + 1)); err == nil {
+		reward.Gold += int(n)
+	}
+
+	byRarity := make(map[string][]Troop)
+	for _, t := range templates {
+		byRarity[t.Rarity] = append(byRarity[t.Rarity], t)
+	}
+
+	for i := 0; i < ct.Cards; i++ {
+		pool := byRarity[weightedPick(ct.Weights)]
+		if len(pool) == 0 {
+			continue
+		}
+		n, err := utils.CryptoRandInt(int64(len(pool)))
+		if err != nil {
+			continue
+		}
+		reward.Cards = append(reward.Cards, CardGrant{Card: pool[n].Name})
+	}
+	return reward
+}
+
+// weightedPick returns a key of weights with probability proportional to
+// its value.
+func weightedPick(weights map[string]int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	n, err := utils.CryptoRandInt(int64(total))
+	if err != nil {
+		n = 0
+	}
+
+	for key, w := range weights {
+		if n < int64(w) {
+			return key
+		}
+		n -= int64(w)
+	}
+	return ""
+}
+
+func rarityOf(templates []Troop, name string) string {
+	for _, t := range templates {
+		if t.Name == name {
+			return t.Rarity
+		}
+	}
+	return "common"
+}
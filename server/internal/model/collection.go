@@ -0,0 +1,210 @@
+// internal/model/collection.go
+
+package model
+
+import (
+	"errors"
+	"sort"
+)
+
+const MaxCardLevel = 10
+
+var (
+	ErrCardNotOwned  = errors.New("card not owned")
+	ErrCardMaxLevel  = errors.New("card is already at max level")
+	ErrNotEnoughGold = errors.New("not enough gold")
+	ErrUnknownCard   = errors.New("unknown card")
+)
+
+// User level at which cards of each rarity join the collection.
+var rarityUnlockLevel = map[string]int{
+	"common":    1,
+	"rare":      3,
+	"epic":      5,
+	"legendary": 8,
+	"champion":  10,
+}
+
+// Gold cost of the first upgrade of each rarity; later levels cost more.
+var rarityUpgradeBase = map[string]int{
+	"common":    50,
+	"rare":      100,
+	"epic":      200,
+	"legendary": 400,
+	"champion":  500,
+}
+
+// ==== STRUCTS ====
+
+type CollectionCard struct {
+	Troop
+	Owned       bool `json:"owned"`
+	UnlockLevel int  `json:"unlock_level"`
+	UpgradeCost int  `json:"upgrade_cost"` // 0 when not owned or maxed
+}
+
+// ==== LEVEL CURVE ====
+
+func CardUnlockLevel(rarity string) int {
+	if lvl, ok := rarityUnlockLevel[rarity]; ok {
+		return lvl
+	}
+	return 1
+}
+
+// UpgradeCost is the gold needed to raise a card from level to level+1.
+func UpgradeCost(rarity string, level int) int {
+	base, ok := rarityUpgradeBase[rarity]
+	if !ok {
+		base = rarityUpgradeBase["common"]
+	}
+	return base * level
+}
+
+// CardStatMultiplier scales a card's base stats for its level.
+func CardStatMultiplier(level int) float64 {
+	if level < 1 {
+		level = 1
+	}
+	return 1 + 0.1*float64(level-1)
+}
+
+// ApplyLevel scales HP, ATK and DMG of a fresh troop copy to the card level.
+func (t *Troop) ApplyLevel(level int) {
+	mult := CardStatMultiplier(level)
+	t.Level = level
+	t.MaxHP *= mult
+	t.HP = t.MaxHP
+	t.ATK *= mult
+	t.DMG *= mult
+}
+
+// ==== USER COLLECTION ====
+
+func (u *User) CardLevel(name string) int {
+	return u.Cards[name]
+}
+
+// UnlockCards adds every card the user's level allows and returns the
+// names of cards that were newly added.
+func (u *User) UnlockCards(templates []Troop) []string {
+	if u.Cards == nil {
+		u.Cards = make(map[string]int)
+	}
+
+	var unlocked []string
+	for _, t := range templates {
+		if _, owned := u.Cards[t.Name]; owned {
+			continue
+		}
+		if u.Level >= CardUnlockLevel(t.Rarity) {
+			u.Cards[t.Name] = 1
+			unlocked = append(unlocked, t.Name)
+		}
+	}
+	sort.Strings(unlocked)
+	return unlocked
+}
+
+// OwnedTroops returns fresh copies of the user's cards at their levels.
+func (u *User) OwnedTroops(templates []Troop) []*Troop {
+	owned := make([]*Troop, 0, len(u.Cards))
+	for _, t := range templates {
+		level, ok := u.Cards[t.Name]
+		if !ok {
+			continue
+		}
+		troop := t
+		troop.ApplyLevel(level)
+		owned = append(owned, &troop)
+	}
+	return owned
+}
+
+// Collection lists every card with the user's ownership and upgrade info.
+func (u *User) Collection(templates []Troop) []CollectionCard {
+	cards := make([]CollectionCard, 0, len(templates))
+	for _, t := range templates {
+		card := CollectionCard{Troop: t, UnlockLevel: CardUnlockLevel(t.Rarity)}
+		if level, ok := u.Cards[t.Name]; ok {
+			card.Owned = true
+			card.Troop.ApplyLevel(level)
+			if level < MaxCardLevel {
+				card.UpgradeCost = UpgradeCost(t.Rarity, level)
+			}
+		}
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// SyncCollection adds any cards the user has unlocked (including the
+// starter set for accounts created before collections) and returns the
+// stored user together with the card templates.
+func SyncCollection(username string) (User, []Troop, error) {
+	templates, err := LoadTroop()
+	if err != nil {
+		return User{}, nil, err
+	}
+
+	user, err := UpdateUser(username, func(u *User) error {
+		u.UnlockCards(templates)
+		return nil
+	})
+	return user, templates, err
+}
+
+// UpgradeCard spends the user's gold to raise one card by a level, as a
+// single atomic update of the user store.
+func UpgradeCard(username, cardName string) (User, int, error) {
+	templates, err := LoadTroop()
+	if err != nil {
+		return User{}, 0, err
+	}
+
+	var template *Troop
+	for i := range templates {
+		if templates[i].Name == cardName {
+			template = &templates[i]
+			break
+		}
+	}
+	if template == nil {
+		return User{}, 0, ErrUnknownCard
+	}
+
+	cost := 0
+	user, err := UpdateUser(username, func(u *User) error {
+		u.UnlockCards(templates)
+
+		level, owned := u.Cards[cardName]
+		if !owned {
+			return ErrCardNotOwned
+		}
+		if level >= MaxCardLevel {
+			return ErrCardMaxLevel
+		}
+
+		cost = UpgradeCost(template.Rarity, level)
+		if u.Gold < cost {
+			return ErrNotEnoughGold
+		}
+
+		u.Gold -= cost
+		u.Cards[cardName] = level + 1
+		return nil
+	})
+	if err != nil {
+		return User{}, 0, err
+	}
+
+	RecordLedger(LedgerEntry{
+		Username: username,
+		Action:   "upgrade_card",
+		Ref:      cardName,
+		Gold:     -cost,
+		Cards:    map[string]int{cardName: user.Cards[cardName]},
+		Balance:  user.Gold,
+	})
+	return user, cost, nil
+}
@@ -0,0 +1,201 @@
+// internal/model/deck.go
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DeckSize          = 8
+	HandSize          = 4 // cards in hand, the rest of the deck is in reserve
+	MaxDecks          = 5
+	maxDeckNameLength = 20
+)
+
+var (
+	ErrDeckNotFound    = errors.New("deck not found")
+	ErrDeckName        = errors.New("invalid deck name")
+	ErrDeckSize        = fmt.Errorf("a deck must have exactly %d cards", DeckSize)
+	ErrDeckDuplicate   = errors.New("deck contains duplicate cards")
+	ErrTooManyDecks    = fmt.Errorf("you can save at most %d decks", MaxDecks)
+	ErrInvalidDeckMode = errors.New("invalid game mode")
+	ErrDeckRarity      = errors.New("deck exceeds a rarity limit")
+)
+
+// Most cards of a rarity a single deck may hold; unlisted rarities are unlimited.
+var rarityDeckLimit = map[string]int{
+	"legendary": 2,
+	"champion":  1,
+}
+
+// ==== STRUCTS ====
+
+type Deck struct {
+	Name  string   `json:"name"`
+	Cards []string `json:"cards"`
+}
+
+type DeckStats struct {
+	AverageMana float64        `json:"average_mana"`
+	Rarities    map[string]int `json:"rarities"`
+}
+
+// ==== VALIDATION ====
+
+// ValidateDeck checks a deck against the user's collection and returns its
+// stats: exactly DeckSize owned cards, no duplicates, within rarity limits.
+func (u *User) ValidateDeck(templates []Troop, deck Deck) (DeckStats, error) {
+	stats := DeckStats{Rarities: make(map[string]int)}
+
+	name := strings.TrimSpace(deck.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxDeckNameLength {
+		return stats, ErrDeckName
+	}
+	if len(deck.Cards) != DeckSize {
+		return stats, ErrDeckSize
+	}
+
+	byName := make(map[string]Troop, len(templates))
+	for _, t := range templates {
+		byName[t.Name] = t
+	}
+
+	seen := make(map[string]bool, DeckSize)
+	totalMana := 0
+	for _, card := range deck.Cards {
+		if seen[card] {
+			return stats, ErrDeckDuplicate
+		}
+		seen[card] = true
+
+		t, ok := byName[card]
+		if !ok {
+			return stats, fmt.Errorf("%w: %s", ErrUnknownCard, card)
+		}
+		if _, owned := u.Cards[card]; !owned {
+			return stats, fmt.Errorf("%w: %s", ErrCardNotOwned, card)
+		}
+
+		stats.Rarities[t.Rarity]++
+		if limit, ok := rarityDeckLimit[t.Rarity]; ok && stats.Rarities[t.Rarity] > limit {
+			return stats, fmt.Errorf("%w: at most %d %s card(s)", ErrDeckRarity, limit, t.Rarity)
+		}
+		totalMana += t.MANA
+	}
+
+	stats.AverageMana = math.Round(float64(totalMana)/float64(DeckSize)*10) / 10
+	return stats, nil
+}
+
+// ==== USER DECKS ====
+
+func (u *User) FindDeck(name string) (Deck, bool) {
+	for _, d := range u.Decks {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Deck{}, false
+}
+
+// ResolveDeck picks the cards to deal for a match: the named deck if given,
+// otherwise the active deck for the mode. It returns nil when the user has
+// no deck for the mode, in which case random owned cards are dealt.
+func (u *User) ResolveDeck(templates []Troop, mode, name string) ([]string, error) {
+	explicit := name != ""
+	if !explicit {
+		name = u.ActiveDecks[mode]
+		if name == "" {
+			return nil, nil
+		}
+	}
+
+	deck, ok := u.FindDeck(name)
+	if !ok {
+		if explicit {
+			return nil, ErrDeckNotFound
+		}
+		return nil, nil
+	}
+
+	if _, err := u.ValidateDeck(templates, deck); err != nil {
+		if explicit {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return deck.Cards, nil
+}
+
+// SaveDeck validates and stores a deck, replacing one with the same name.
+func SaveDeck(username string, deck Deck) (User, DeckStats, error) {
+	templates, err := LoadTroop()
+	if err != nil {
+		return User{}, DeckStats{}, err
+	}
+
+	deck.Name = strings.TrimSpace(deck.Name)
+	var stats DeckStats
+	user, err := UpdateUser(username, func(u *User) error {
+		u.UnlockCards(templates)
+
+		stats, err = u.ValidateDeck(templates, deck)
+		if err != nil {
+			return err
+		}
+
+		for i := range u.Decks {
+			if u.Decks[i].Name == deck.Name {
+				u.Decks[i] = deck
+				return nil
+			}
+		}
+		if len(u.Decks) >= MaxDecks {
+			return ErrTooManyDecks
+		}
+		u.Decks = append(u.Decks, deck)
+		return nil
+	})
+	return user, stats, err
+}
+
+func DeleteDeck(username, name string) (User, error) {
+	return UpdateUser(username, func(u *User) error {
+		for i := range u.Decks {
+			if u.Decks[i].Name != name {
+				continue
+			}
+			u.Decks = append(u.Decks[:i], u.Decks[i+1:]...)
+			for mode, active := range u.ActiveDecks {
+				if active == name {
+					delete(u.ActiveDecks, mode)
+				}
+			}
+			return nil
+		}
+		return ErrDeckNotFound
+	})
+}
+
+// SetActiveDeck selects the deck used for a mode when find_match names none.
+func SetActiveDeck(username, mode, name string) (User, error) {
+	if mode != "simple" && mode != "enhanced" {
+		return User{}, ErrInvalidDeckMode
+	}
+
+	return UpdateUser(username, func(u *User) error {
+		if _, ok := u.FindDeck(name); !ok {
+			return ErrDeckNotFound
+		}
+		if u.ActiveDecks == nil {
+			u.ActiveDecks = make(map[string]string)
+		}
+		u.ActiveDecks[mode] = name
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+// internal/model/guest.go
+
+package model
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// GuestPrefix starts every generated guest name. Registration rejects
+// names with it, so guest names never collide with registered ones.
+const GuestPrefix = "Guest"
+
+// guestNameTries is how many generated names are tried before giving up.
+const guestNameTries = 5
+
+var ErrNotGuest = errors.New("account is not a guest")
+
+// NewGuest stores a guest user under a generated name like Guest482913.
+// Guests have no password; their session is their only credential.
+func NewGuest() (User, error) {
+	for range guestNameTries {
+		n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		if err != nil {
+			return User{}, err
+		}
+		guest := NewUser(fmt.Sprintf("%s%06d", GuestPrefix, n.Int64()), "")
+		guest.Guest = true
+
+		err = AddUser(*guest)
+		if errors.Is(err, ErrUserExists) {
+			continue
+		}
+		return *guest, err
+	}
+	return User{}, ErrUserExists
+}
+
+// ClaimGuest turns a guest into a registered user with a username and a
+// hashed password, keeping their progress, friends and match history.
+func ClaimGuest(guestName, username, hashedPassword string) (User, error) {
+	return renameUser(guestName, username, func(u *User) error {
+		if !u.Guest {
+			return ErrNotGuest
+		}
+		u.Guest = false
+		u.Password = hashedPassword
+		return nil
+	})
+}
+
+// IdleGuests returns the guests that have not logged in since cutoff.
+func IdleGuests(cutoff time.Time) ([]string, error) {
+	users, err := LoadUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	var idle []string
+	for _, u := range users {
+		if u.Guest && u.LastLogin.Before(cutoff) {
+			idle = append(idle, u.Username)
+		}
+	}
+	return idle, nil
+}
@@ -0,0 +1,166 @@
+// internal/model/leaderboard.go
+
+package model
+
+import (
+	"sort"
+	"sync"
+)
+
+// ==== STRUCTS ====
+
+type LeaderboardEntry struct {
+	Rank        int    `json:"rank"`
+	Username    string `json:"username"`
+	Avatar      string `json:"avatar"`
+	Level       int    `json:"level"`
+	EXP         int    `json:"exp"`
+	Trophies    int    `json:"trophies"`
+	Arena       string `json:"arena"`
+	GamesPlayed int    `json:"gamesPlayed"`
+	GamesWon    int    `json:"gamesWon"`
+}
+
+// The leaderboard is kept in memory and rebuilt from the users slice every
+// time the store is written, so reads never touch users.json.
+var (
+	board       []LeaderboardEntry
+	boardRank   = make(map[string]int)      // username -> index in board
+	boardFriend = make(map[string][]string) // username -> friends
+	boardLoaded bool
+	boardMu     sync.RWMutex
+)
+
+// refreshLeaderboard rebuilds the cache; saveUsers calls it after writing.
+func refreshLeaderboard(users []User) {
+	entries := make([]LeaderboardEntry, 0, len(users))
+	friends := make(map[string][]string, len(users))
+	for _, u := range users {
+		entries = append(entries, LeaderboardEntry{
+			Username:    u.Username,
+			Avatar:      u.Avatar,
+			Level:       u.Level,
+			EXP:         u.EXP,
+			Trophies:    u.Trophies,
+			Arena:       ArenaFor(u.Trophies).Name,
+			GamesPlayed: u.GamesPlayed,
+			GamesWon:    u.GamesWon,
+		})
+		friends[u.Username] = append([]string(nil), u.Friends...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Trophies != b.Trophies {
+			return a.Trophies > b.Trophies
+		}
+		if a.Level != b.Level {
+			return a.Level > b.Level
+		}
+		if a.EXP != b.EXP {
+			return a.EXP > b.EXP
+		}
+		return a.Username < b.Username
+	})
+
+	ranks := make(map[string]int, len(entries))
+	for i := range entries {
+		entries[i].Rank = i + 1
+		ranks[entries[i].Username] = i
+	}
+
+	boardMu.Lock()
+	board, boardRank, boardFriend, boardLoaded = entries, ranks, friends, true
+	boardMu.Unlock()
+}
+
+// ensureLeaderboard fills the cache on first use after startup.
+func ensureLeaderboard() error {
+	boardMu.RLock()
+	loaded := boardLoaded
+	boardMu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	users, err := LoadUsers()
+	if err != nil {
+		return err
+	}
+	refreshLeaderboard(users)
+	return nil
+}
+
+// Leaderboard returns one page of the global trophy ladder along with the
+// total number of ranked users.
+func Leaderboard(offset, limit int) ([]LeaderboardEntry, int, error) {
+	if err := ensureLeaderboard(); err != nil {
+		return nil, 0, err
+	}
+
+	boardMu.RLock()
+	defer boardMu.RUnlock()
+	return paginate(board, offset, limit), len(board), nil
+}
+
+// FriendsLeaderboard ranks the user and their friends against each other.
+// Rank is the position among friends; global placement is not exposed here.
+func FriendsLeaderboard(username string, offset, limit int) ([]LeaderboardEntry, int, error) {
+	if err := ensureLeaderboard(); err != nil {
+		return nil, 0, err
+	}
+
+	boardMu.RLock()
+	defer boardMu.RUnlock()
+
+	idx, ok := boardRank[username]
+	if !ok {
+		return nil, 0, ErrUserNotFound
+	}
+
+	indexes := []int{idx}
+	for _, friend := range boardFriend[username] {
+		if i, ok := boardRank[friend]; ok {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	entries := make([]LeaderboardEntry, 0, len(indexes))
+	for n, i := range indexes {
+		e := board[i]
+		e.Rank = n + 1
+		entries = append(entries, e)
+	}
+	return paginate(entries, offset, limit), len(entries), nil
+}
+
+// LeaderboardRank returns the user's global ladder entry.
+func LeaderboardRank(username string) (LeaderboardEntry, bool) {
+	if err := ensureLeaderboard(); err != nil {
+		return LeaderboardEntry{}, false
+	}
+
+	boardMu.RLock()
+	defer boardMu.RUnlock()
+	i, ok := boardRank[username]
+	if !ok {
+		return LeaderboardEntry{}, false
+	}
+	return board[i], true
+}
+
+func paginate(entries []LeaderboardEntry, offset, limit int) []LeaderboardEntry {
+	total := len(entries)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if limit <= 0 || end > total {
+		end = total
+	}
+	return append([]LeaderboardEntry(nil), entries[offset:end]...)
+}
@@ -0,0 +1,56 @@
+// internal/model/ledger.go
+
+package model
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ledgerFile = "assets/data/ledger.jsonl"
+	ledgerMu   sync.Mutex
+)
+
+// LedgerEntry records one change to a user's gold or cards outside of a
+// match, so every purchase and reward can be audited later.
+type LedgerEntry struct {
+	Time     time.Time      `json:"time"`
+	Username string         `json:"username"`
+	Action   string         `json:"action"`          // shop_purchase, chest_open, upgrade_card, quest_reward, season_reward
+	Ref      string         `json:"ref,omitempty"`   // offer, chest or card involved
+	Gold     int            `json:"gold"`            // signed change in gold
+	Cards    map[string]int `json:"cards,omitempty"` // card -> level after the change
+	Balance  int            `json:"balance"`         // gold after the change
+}
+
+// RecordLedger appends an entry as one JSON line. Failures are logged but do
+// not undo the already committed change.
+func RecordLedger(entry LedgerEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("[ERROR][LEDGER] Failed to encode entry for %s: %v", entry.Username, err)
+		return
+	}
+
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+
+	file, err := os.OpenFile(ledgerFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("[ERROR][LEDGER] Failed to open ledger: %v", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		log.Printf("[ERROR][LEDGER] Failed to write entry for %s: %v", entry.Username, err)
+	}
+}
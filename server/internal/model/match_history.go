@@ -0,0 +1,336 @@
+// internal/model/match_history.go
+
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// End reasons stored with every finished match.
+const (
+	EndKingDestroyed = "king_destroyed"
+	EndScore         = "score"   // time ran out, fewer towers lost wins
+	EndTimeout       = "timeout" // time ran out with equal towers and tower health
+	EndSuddenDeath   = "sudden_death"
+	EndTiebreak      = "tiebreak" // decided by the weakest tower's health
+	EndForfeit       = "forfeit"  // a player left or disconnected
+	EndShutdown      = "shutdown" // the server stopped before the match ended
+	EndAdmin         = "admin"    // ended by an administrator
+)
+
+var ErrMatchNotFound = errors.New("match not found")
+
+var (
+	matchesFile = "assets/data/matches.jsonl"
+
+	// Finished matches are appended to matchesFile and indexed in memory,
+	// newest last, the first time history is needed.
+	matchByID     = make(map[string]*MatchRecord)
+	matchesByUser = make(map[string][]*MatchRecord)
+	matchesLoaded bool
+	matchesMu     sync.RWMutex
+)
+
+// ==== STRUCTS ====
+
+type MatchPlayer struct {
+	Username        string     `json:"username"`
+	Deck            []string   `json:"deck"`
+	TowersDestroyed int        `json:"towers_destroyed"`
+	Gold            int        `json:"gold"`
+	TrophyDelta     int        `json:"trophy_delta"`
+	Stats           MatchStats `json:"stats"`
+}
+
+type MatchRecord struct {
+	ID         string        `json:"id"`
+	Mode       string        `json:"mode"`
+	StartedAt  time.Time     `json:"started_at"`
+	EndedAt    time.Time     `json:"ended_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Winner     string        `json:"winner,omitempty"` // empty on a draw
+	EndReason  string        `json:"end_reason"`
+	Players    []MatchPlayer `json:"players"`
+	HasReplay  bool          `json:"has_replay"`
+}
+
+type WinRate struct {
+	Played int     `json:"played"`
+	Won    int     `json:"won"`
+	Rate   float64 `json:"rate"` // percentage, one decimal
+}
+
+type MatchSummary struct {
+	ByMode map[string]*WinRate `json:"by_mode"`
+	ByCard map[string]*WinRate `json:"by_card"`
+}
+
+// Player returns the record's entry for username.
+func (m *MatchRecord) Player(username string) (MatchPlayer, bool) {
+	for _, p := range m.Players {
+		if p.Username == username {
+			return p, true
+		}
+	}
+	return MatchPlayer{}, false
+}
+
+// ==== STORE ====
+
+func loadMatches() error {
+	if matchesLoaded {
+		return nil
+	}
+
+	file, err := os.Open(matchesFile)
+	if os.IsNotExist(err) {
+		matchesLoaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var m MatchRecord
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			log.Printf("[WARN][HISTORY] Skipping unreadable match record: %v", err)
+			continue
+		}
+		indexMatch(&m)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	matchesLoaded = true
+	return nil
+}
+
+func ensureMatches() error {
+	matchesMu.Lock()
+	defer matchesMu.Unlock()
+	return loadMatches()
+}
+
+func indexMatch(m *MatchRecord) {
+	matchByID[m.ID] = m
+	for _, p := range m.Players {
+		matchesByUser[p.Username] = append(matchesByUser[p.Username], m)
+	}
+}
+
+// NewMatchID returns an ID for a match that is about to be recorded, so its
+// replay can be stored under the same name.
+func NewMatchID() string {
+	return generateID()
+}
+
+// RecordMatch assigns the match an ID if it has none, appends it to the history file and
+// indexes it.
+func RecordMatch(m MatchRecord) (MatchRecord, error) {
+	matchesMu.Lock()
+	defer matchesMu.Unlock()
+
+	if err := loadMatches(); err != nil {
+		return m, err
+	}
+
+	if m.ID == "" {
+		m.ID = generateID()
+	}
+	line, err := json.Marshal(m)
+	if err != nil {
+		return m, err
+	}
+
+	file, err := os.OpenFile(matchesFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return m, err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return m, err
+	}
+
+	indexMatch(&m)
+	return m, nil
+}
+
+// MatchHistory returns a page of the user's matches, newest first, with the
+// total number of matches they played.
+func MatchHistory(username string, offset, limit int) ([]MatchRecord, int, error) {
+	if err := ensureMatches(); err != nil {
+		return nil, 0, err
+	}
+
+	matchesMu.RLock()
+	defer matchesMu.RUnlock()
+
+	all := matchesByUser[username]
+	total := len(all)
+	offset = max(0, min(offset, total))
+	end := total
+	if limit > 0 {
+		end = min(total, offset+limit)
+	}
+
+	page := make([]MatchRecord, 0, end-offset)
+	for i := offset; i < end; i++ {
+		page = append(page, *all[total-1-i])
+	}
+	return page, total, nil
+}
+
+func FindMatch(id string) (MatchRecord, error) {
+	if err := ensureMatches(); err != nil {
+		return MatchRecord{}, err
+	}
+
+	matchesMu.RLock()
+	defer matchesMu.RUnlock()
+	m, ok := matchByID[id]
+	if !ok {
+		return MatchRecord{}, ErrMatchNotFound
+	}
+	return *m, nil
+}
+
+// SummarizeMatches computes the user's win rates by mode and by card over
+// their whole history. Draws count as played but not won.
+func SummarizeMatches(username string) (MatchSummary, error) {
+	if err := ensureMatches(); err != nil {
+		return MatchSummary{}, err
+	}
+
+	summary := MatchSummary{
+		ByMode: make(map[string]*WinRate),
+		ByCard: make(map[string]*WinRate),
+	}
+
+	matchesMu.RLock()
+	for _, m := range matchesByUser[username] {
+		p, ok := m.Player(username)
+		if !ok {
+			continue
+		}
+		won := m.Winner == username
+
+		tally(summary.ByMode, m.Mode, won)
+		for _, card := range p.Deck {
+			tally(summary.ByCard, card, won)
+		}
+	}
+	matchesMu.RUnlock()
+
+	for _, r := range summary.ByMode {
+		r.Rate = winPercent(r)
+	}
+	for _, r := range summary.ByCard {
+		r.Rate = winPercent(r)
+	}
+	return summary, nil
+}
+
+func tally(rates map[string]*WinRate, key string, won bool) {
+	r, ok := rates[key]
+	if !ok {
+		r = &WinRate{}
+		rates[key] = r
+	}
+	r.Played++
+	if won {
+		r.Won++
+	}
+}
+
+func winPercent(r *WinRate) float64 {
+	if r.Played == 0 {
+		return 0
+	}
+	return math.Round(float64(r.Won)/float64(r.Played)*1000) / 10
+}
+
+// renameInMatches replaces a player's name in every match they played and
+// rewrites the history file.
+func renameInMatches(oldName, newName string) error {
+	matchesMu.Lock()
+	defer matchesMu.Unlock()
+
+	if err := loadMatches(); err != nil {
+		return err
+	}
+	played := matchesByUser[oldName]
+	if len(played) == 0 {
+		return nil
+	}
+
+	for _, m := range played {
+		for i := range m.Players {
+			if m.Players[i].Username == oldName {
+				m.Players[i].Username = newName
+			}
+		}
+		if m.Winner == oldName {
+			m.Winner = newName
+		}
+	}
+	delete(matchesByUser, oldName)
+	matchesByUser[newName] = append(matchesByUser[newName], played...)
+	slices.SortStableFunc(matchesByUser[newName], func(a, b *MatchRecord) int {
+		return a.EndedAt.Compare(b.EndedAt)
+	})
+
+	return writeMatches()
+}
+
+// writeMatches replaces the history file with the indexed matches, oldest
+// first. The caller holds matchesMu.
+func writeMatches() error {
+	all := make([]*MatchRecord, 0, len(matchByID))
+	for _, m := range matchByID {
+		all = append(all, m)
+	}
+	slices.SortStableFunc(all, func(a, b *MatchRecord) int {
+		return a.EndedAt.Compare(b.EndedAt)
+	})
+
+	var data []byte
+	for _, m := range all {
+		line, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return writeFileAtomic(matchesFile, data)
+}
+
+// replayMatchIDs lists the matches a user played that kept a replay.
+func replayMatchIDs(username string) ([]string, error) {
+	if err := ensureMatches(); err != nil {
+		return nil, err
+	}
+
+	matchesMu.RLock()
+	defer matchesMu.RUnlock()
+	ids := make([]string, 0, len(matchesByUser[username]))
+	for _, m := range matchesByUser[username] {
+		if m.HasReplay {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids, nil
+}
@@ -22,6 +22,8 @@ type Player struct {
 	Turn           int               `json:"turn"`
 	LastManaRegen  time.Time         `json:"-"`
 	Gold           int               `json:"gold"`
+	Deck           []string          `json:"-"` // names of the cards dealt for this match
+	Stats          MatchStats        `json:"-"`
 }
 
 var (
@@ -35,7 +37,9 @@ var (
 
 // ==== CONSTRUCTOR ====
 
-func NewPlayer(user *User, mode string) *Player {
+// NewPlayer deals the player's hand from deck (card names, usually from
+// User.ResolveDeck); a nil deck deals random cards from the collection.
+func NewPlayer(user *User, mode string, deck []string) *Player {
 	if mode != "simple" && mode != "enhanced" {
 		return nil
 	}
@@ -44,20 +48,18 @@ func NewPlayer(user *User, mode string) *Player {
 	var troopQueue []*Troop
 	var troopInstances []*TroopInstance
 
-	if mode == "simple" {
-		troops = getRandomTroops(4)
-	} else {
-		allTroops := getRandomTroops(8)
-		shuffled := shuffleTroops(allTroops)
-		troops = shuffled[:4]
-		troopQueue = shuffled[4:]
+	// The rest of the deck waits in reserve, drawn in enhanced mode and
+	// swapped in simple mode
+	shuffled := getDeckTroops(user, deck, DeckSize)
+	troops = shuffled[:min(HandSize, len(shuffled))]
+	troopQueue = shuffled[len(troops):]
+	if mode == "enhanced" {
 		troopInstances = createTroopInstances(troops, user.Username)
 	}
 
 	towers := LoadTower()
 
 	player := &Player{
-		Mana: 5,
 		Towers: map[string]*Tower{
 			"king": func() *Tower {
 				t := towers["King Tower"].Clone(mode, user.Level)
@@ -85,6 +87,8 @@ func NewPlayer(user *User, mode string) *Player {
 		Turn:           0,
 		LastManaRegen:  time.Now(),
 		Gold:           0,
+		Deck:           troopNames(troops, troopQueue),
+		Stats:          MatchStats{HealerInDeck: hasTroopType("healer", troops, troopQueue)},
 	}
 
 	return player
@@ -92,6 +96,27 @@ func NewPlayer(user *User, mode string) *Player {
 
 // ==== PLAYER METHODS ====
 
+func troopNames(groups ...[]*Troop) []string {
+	var names []string
+	for _, troops := range groups {
+		for _, t := range troops {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+func hasTroopType(troopType string, groups ...[]*Troop) bool {
+	for _, troops := range groups {
+		for _, t := range troops {
+			if t.Type == troopType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (p *Player) RotateTroop(usedTroopName string) {
 	usedIndex := -1
 	for i, t := range p.Troops {
@@ -124,6 +149,18 @@ func (p *Player) DestroyedCount() int {
 	return count
 }
 
+// WeakestTowerPercent returns the lowest health, as a percentage of max HP,
+// among the player's standing towers.
+func (p *Player) WeakestTowerPercent() float64 {
+	weakest := 100.0
+	for _, t := range p.Towers {
+		if t.HP > 0 && t.MaxHP > 0 {
+			weakest = min(weakest, t.HP/t.MaxHP*100)
+		}
+	}
+	return weakest
+}
+
 // ==== CONNECTION MANAGEMENT ====
 
 func RegisterConnection(conn *websocket.Conn, player *Player) {
@@ -0,0 +1,261 @@
+// internal/model/quest.go
+
+package model
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dailyQuestCount = 3
+
+// Metrics counted from match events; quests and achievements track them.
+const (
+	MetricMatches       = "matches"
+	MetricWins          = "wins"
+	MetricWinWithHealer = "win_with_healer"
+	MetricCardsPlayed   = "cards_played"
+	MetricTroopsKilled  = "troops_killed"
+	MetricGuardTowers   = "guard_towers"
+	MetricKingTowers    = "king_towers"
+	MetricHeals         = "heals"
+)
+
+// ==== STRUCTS ====
+
+// MatchStats counts what one player did during a match.
+type MatchStats struct {
+	Won          bool `json:"won"`
+	Draw         bool `json:"draw"`
+	HealerInDeck bool `json:"healer_in_deck"`
+	CardsPlayed  int  `json:"cards_played"`
+	TroopsKilled int  `json:"troops_killed"`
+	GuardTowers  int  `json:"guard_towers"`
+	KingTowers   int  `json:"king_towers"`
+	Heals        int  `json:"heals"`
+}
+
+type Quest struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Metric      string `json:"metric"`
+	Target      int    `json:"target"`
+	RewardEXP   int    `json:"reward_exp"`
+	RewardGold  int    `json:"reward_gold"`
+}
+
+type Achievement struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Metric     string `json:"metric"`
+	Tiers      []int  `json:"tiers"`
+	RewardEXP  []int  `json:"reward_exp"`
+	RewardGold []int  `json:"reward_gold"`
+}
+
+// QuestLog is the user's progress on the current day's quests.
+type QuestLog struct {
+	Day       string         `json:"day"`
+	Progress  map[string]int `json:"progress"`
+	Completed []string       `json:"completed,omitempty"`
+}
+
+type QuestProgress struct {
+	Quest
+	Progress  int  `json:"progress"`
+	Completed bool `json:"completed"`
+}
+
+type AchievementProgress struct {
+	Achievement
+	Progress int `json:"progress"`
+	Tier     int `json:"tier"` // number of tiers reached
+}
+
+// ProgressReport is sent to a player after each match.
+type ProgressReport struct {
+	Quests       []QuestProgress       `json:"quests"`
+	Achievements []AchievementProgress `json:"achievements"`
+	Completed    []string              `json:"completed"`     // quest IDs finished by this match
+	TiersReached []string              `json:"tiers_reached"` // "achievement:tier" reached by this match
+	RewardEXP    int                   `json:"reward_exp"`
+	RewardGold   int                   `json:"reward_gold"`
+	Level        int                   `json:"level"`
+	EXP          int                   `json:"exp"`
+	Gold         int                   `json:"gold"`
+}
+
+var questPool = []Quest{
+	{ID: "play_matches", Description: "Play 5 matches", Metric: MetricMatches, Target: 5, RewardEXP: 30, RewardGold: 40},
+	{ID: "win_matches", Description: "Win 3 matches", Metric: MetricWins, Target: 3, RewardEXP: 50, RewardGold: 80},
+	{ID: "win_with_healer", Description: "Win with a healer in your deck", Metric: MetricWinWithHealer, Target: 1, RewardEXP: 40, RewardGold: 50},
+	{ID: "play_cards", Description: "Play 20 cards", Metric: MetricCardsPlayed, Target: 20, RewardEXP: 30, RewardGold: 40},
+	{ID: "defeat_troops", Description: "Defeat 15 enemy troops", Metric: MetricTroopsKilled, Target: 15, RewardEXP: 30, RewardGold: 50},
+	{ID: "destroy_guards", Description: "Destroy 5 guard towers", Metric: MetricGuardTowers, Target: 5, RewardEXP: 40, RewardGold: 60},
+	{ID: "destroy_king", Description: "Destroy a King Tower", Metric: MetricKingTowers, Target: 1, RewardEXP: 40, RewardGold: 60},
+	{ID: "heal_towers", Description: "Heal your towers 5 times", Metric: MetricHeals, Target: 5, RewardEXP: 30, RewardGold: 40},
+}
+
+var achievements = []Achievement{
+	{ID: "veteran", Name: "Veteran", Metric: MetricMatches,
+		Tiers: []int{10, 100, 500}, RewardEXP: []int{50, 150, 400}, RewardGold: []int{100, 300, 1000}},
+	{ID: "conqueror", Name: "Conqueror", Metric: MetricWins,
+		Tiers: []int{10, 50, 200}, RewardEXP: []int{80, 200, 500}, RewardGold: []int{150, 500, 1500}},
+	{ID: "commander", Name: "Commander", Metric: MetricCardsPlayed,
+		Tiers: []int{100, 1000, 5000}, RewardEXP: []int{50, 150, 400}, RewardGold: []int{100, 300, 1000}},
+	{ID: "slayer", Name: "Slayer", Metric: MetricTroopsKilled,
+		Tiers: []int{50, 500, 2500}, RewardEXP: []int{50, 150, 400}, RewardGold: []int{100, 300, 1000}},
+	{ID: "demolisher", Name: "Demolisher", Metric: MetricGuardTowers,
+		Tiers: []int{10, 100, 500}, RewardEXP: []int{60, 180, 450}, RewardGold: []int{120, 350, 1200}},
+	{ID: "regicide", Name: "Regicide", Metric: MetricKingTowers,
+		Tiers: []int{5, 25, 100}, RewardEXP: []int{80, 200, 500}, RewardGold: []int{150, 500, 1500}},
+}
+
+// Metrics converts the stats into metric increments.
+func (s MatchStats) Metrics() map[string]int {
+	m := map[string]int{
+		MetricMatches:      1,
+		MetricCardsPlayed:  s.CardsPlayed,
+		MetricTroopsKilled: s.TroopsKilled,
+		MetricGuardTowers:  s.GuardTowers,
+		MetricKingTowers:   s.KingTowers,
+		MetricHeals:        s.Heals,
+	}
+	if s.Won {
+		m[MetricWins] = 1
+		if s.HealerInDeck {
+			m[MetricWinWithHealer] = 1
+		}
+	}
+	return m
+}
+
+// ==== DAILY QUESTS ====
+
+// dayRand returns a generator seeded by the UTC day, so a daily rotation is
+// the same for every player and survives restarts.
+func dayRand(now time.Time, salt string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(ShopDay(now) + salt))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+func DailyQuests(now time.Time) []Quest {
+	rng := dayRand(now, "quests")
+	order := rng.Perm(len(questPool))
+
+	quests := make([]Quest, 0, dailyQuestCount)
+	for _, i := range order[:dailyQuestCount] {
+		quests = append(quests, questPool[i])
+	}
+	return quests
+}
+
+// refreshQuests starts a new quest log when the day has rolled over.
+func (u *User) refreshQuests(now time.Time) {
+	day := ShopDay(now)
+	if u.Quests == nil || u.Quests.Day != day {
+		u.Quests = &QuestLog{Day: day, Progress: make(map[string]int)}
+	}
+}
+
+func (u *User) QuestStatus(now time.Time) []QuestProgress {
+	var today QuestLog
+	if u.Quests != nil && u.Quests.Day == ShopDay(now) {
+		today = *u.Quests
+	}
+
+	quests := DailyQuests(now)
+	status := make([]QuestProgress, 0, len(quests))
+	for _, q := range quests {
+		progress := min(today.Progress[q.ID], q.Target)
+		status = append(status, QuestProgress{
+			Quest:     q,
+			Progress:  progress,
+			Completed: slices.Contains(today.Completed, q.ID),
+		})
+	}
+	return status
+}
+
+func (u *User) AchievementStatus() []AchievementProgress {
+	status := make([]AchievementProgress, 0, len(achievements))
+	for _, a := range achievements {
+		status = append(status, AchievementProgress{
+			Achievement: a,
+			Progress:    u.Records[a.Metric],
+			Tier:        u.Achievements[a.ID],
+		})
+	}
+	return status
+}
+
+// ApplyMatchStats adds a match to the user's quests and achievements and
+// pays out anything it completed, as a single atomic update.
+func ApplyMatchStats(username string, stats MatchStats) (ProgressReport, error) {
+	now := time.Now()
+	var report ProgressReport
+
+	user, err := UpdateUser(username, func(u *User) error {
+		report = ProgressReport{Completed: []string{}, TiersReached: []string{}}
+		metrics := stats.Metrics()
+
+		u.refreshQuests(now)
+		for _, q := range DailyQuests(now) {
+			if slices.Contains(u.Quests.Completed, q.ID) {
+				continue
+			}
+			u.Quests.Progress[q.ID] += metrics[q.Metric]
+			if u.Quests.Progress[q.ID] >= q.Target {
+				u.Quests.Completed = append(u.Quests.Completed, q.ID)
+				report.Completed = append(report.Completed, q.ID)
+				report.RewardEXP += q.RewardEXP
+				report.RewardGold += q.RewardGold
+			}
+		}
+
+		if u.Records == nil {
+			u.Records = make(map[string]int)
+		}
+		if u.Achievements == nil {
+			u.Achievements = make(map[string]int)
+		}
+		for metric, n := range metrics {
+			u.Records[metric] += n
+		}
+		for _, a := range achievements {
+			for tier := u.Achievements[a.ID]; tier < len(a.Tiers) && u.Records[a.Metric] >= a.Tiers[tier]; tier++ {
+				u.Achievements[a.ID] = tier + 1
+				report.TiersReached = append(report.TiersReached, a.ID+":"+strconv.Itoa(tier+1))
+				report.RewardEXP += a.RewardEXP[tier]
+				report.RewardGold += a.RewardGold[tier]
+			}
+		}
+
+		u.AddExp(report.RewardEXP)
+		u.Gold += report.RewardGold
+		return nil
+	})
+	if err != nil {
+		return ProgressReport{}, err
+	}
+
+	if report.RewardGold > 0 {
+		RecordLedger(LedgerEntry{
+			Username: username,
+			Action:   "quest_reward",
+			Ref:      strings.Join(append(report.Completed, report.TiersReached...), ","),
+			Gold:     report.RewardGold,
+			Balance:  user.Gold,
+		})
+	}
+
+	report.Quests = user.QuestStatus(now)
+	report.Achievements = user.AchievementStatus()
+	report.Level, report.EXP, report.Gold = user.Level, user.EXP, user.Gold
+	return report, nil
+}
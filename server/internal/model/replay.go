@@ -0,0 +1,182 @@
+// internal/model/replay.go
+
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	replaysDir     = "assets/data/replays"
+	replayIDFormat = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+// ==== STRUCTS ====
+
+// ReplayEvent is one message the room broadcast during the match.
+type ReplayEvent struct {
+	At      int64           `json:"at"` // milliseconds since the match started
+	Type    string          `json:"type"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type Replay struct {
+	MatchID   string          `json:"match_id"`
+	Mode      string          `json:"mode"`
+	StartedAt time.Time       `json:"started_at"`
+	Players   []string        `json:"players"`
+	Events    []ReplayEvent   `json:"events"`
+	Chat      json.RawMessage `json:"chat"`
+}
+
+// ==== STORE ====
+
+// ReplayPath is where a match's gzipped replay is stored.
+func ReplayPath(matchID string) (string, error) {
+	if !replayIDFormat.MatchString(matchID) {
+		return "", ErrMatchNotFound
+	}
+	return filepath.Join(replaysDir, matchID+".json.gz"), nil
+}
+
+func SaveReplay(replay Replay) error {
+	path, err := ReplayPath(replay.MatchID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(replaysDir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	if err := json.NewEncoder(zw).Encode(replay); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// OpenReplay opens the gzipped replay of a match for streaming.
+func OpenReplay(matchID string) (*os.File, error) {
+	path, err := ReplayPath(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrMatchNotFound
+	}
+	return file, err
+}
+
+// renameInReplays replaces a user's name in the replays of their matches,
+// in names, messages and chat alike. It runs before renameInMatches, which
+// moves the matches to the new name.
+func renameInReplays(oldName, newName string) error {
+	ids, err := replayMatchIDs(oldName)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := rewriteReplay(id, func(s string) string {
+			return replaceName(s, oldName, newName)
+		}); err != nil {
+			return fmt.Errorf("replay %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// replaceName replaces whole occurrences of name in s: "bob" but not the
+// start of "bobby".
+func replaceName(s, name, replacement string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, name)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(name)
+		if (i == 0 || !isNameByte(s[i-1])) && (end == len(s) || !isNameByte(s[end])) {
+			b.WriteString(s[:i])
+			b.WriteString(replacement)
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '-' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// rewriteReplay applies fn to every string value in a stored replay.
+func rewriteReplay(matchID string, fn func(string) string) error {
+	file, err := OpenReplay(matchID)
+	if errors.Is(err, ErrMatchNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	var replay any
+	err = json.NewDecoder(zr).Decode(&replay)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(mapStrings(replay, fn)); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	path, _ := ReplayPath(matchID)
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// mapStrings applies fn to the string values, not the keys, of a decoded
+// JSON document.
+func mapStrings(v any, fn func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case map[string]any:
+		for key, field := range v {
+			v[key] = mapStrings(field, fn)
+		}
+	case []any:
+		for i := range v {
+			v[i] = mapStrings(v[i], fn)
+		}
+	}
+	return v
+}
@@ -0,0 +1,164 @@
+// internal/model/report.go
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Report statuses.
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"  // action was taken against the reported player
+	ReportDismissed = "dismissed" // no action needed
+)
+
+// ReportReasons are the reasons players can pick when reporting.
+var ReportReasons = []string{"cheating", "abusive_chat", "afk", "unsportsmanlike", "other"}
+
+var (
+	ErrReportNotFound  = errors.New("report not found")
+	ErrAlreadyReported = errors.New("already reported this match")
+	ErrReportNotOpen   = errors.New("report already handled")
+	ErrInvalidStatus   = errors.New("invalid report status")
+)
+
+var (
+	reportsFile = "assets/data/reports.json"
+	reportsMu   sync.Mutex
+)
+
+// Report is a player's complaint about their opponent in a finished match.
+type Report struct {
+	ID        string    `json:"id"`
+	MatchID   string    `json:"match_id"`
+	Reporter  string    `json:"reporter"`
+	Reported  string    `json:"reported"`
+	Reason    string    `json:"reason"`
+	Details   string    `json:"details,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+
+	HandledBy string     `json:"handled_by,omitempty"`
+	HandledAt *time.Time `json:"handled_at,omitempty"`
+	Action    string     `json:"action,omitempty"` // what was done, e.g. "suspension 24h0m0s"
+	Note      string     `json:"note,omitempty"`
+}
+
+func loadReports() ([]Report, error) {
+	data, err := os.ReadFile(reportsFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func saveReports(reports []Report) error {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(reportsFile, data)
+}
+
+// AddReport stores a new open report. A player can report each match once.
+func AddReport(report Report) (Report, error) {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+
+	reports, err := loadReports()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, r := range reports {
+		if r.MatchID == report.MatchID && r.Reporter == report.Reporter {
+			return Report{}, ErrAlreadyReported
+		}
+	}
+
+	report.ID = generateID()
+	report.Status = ReportOpen
+	report.CreatedAt = time.Now()
+	if err := saveReports(append(reports, report)); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+// Reports lists the reports with the given status, or all of them when
+// status is empty, oldest first.
+func Reports(status string) ([]Report, error) {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+
+	reports, err := loadReports()
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		reports = slices.DeleteFunc(reports, func(r Report) bool { return r.Status != status })
+	}
+	return reports, nil
+}
+
+func FindReport(id string) (Report, error) {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+
+	reports, err := loadReports()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, r := range reports {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return Report{}, ErrReportNotFound
+}
+
+// CloseReport marks an open report resolved or dismissed.
+func CloseReport(id, status, action, note, by string) (Report, error) {
+	if status != ReportResolved && status != ReportDismissed {
+		return Report{}, ErrInvalidStatus
+	}
+
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+
+	reports, err := loadReports()
+	if err != nil {
+		return Report{}, err
+	}
+	i := slices.IndexFunc(reports, func(r Report) bool { return r.ID == id })
+	if i < 0 {
+		return Report{}, ErrReportNotFound
+	}
+	if reports[i].Status != ReportOpen {
+		return Report{}, ErrReportNotOpen
+	}
+
+	now := time.Now()
+	reports[i].Status = status
+	reports[i].Action = action
+	reports[i].Note = note
+	reports[i].HandledBy = by
+	reports[i].HandledAt = &now
+	if err := saveReports(reports); err != nil {
+		return Report{}, err
+	}
+	return reports[i], nil
+}
@@ -0,0 +1,129 @@
+// internal/model/rules.go
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidRules = errors.New("invalid match rules")
+
+// ==== STRUCTS ====
+
+// Duration is a time.Duration written as a Go duration string ("30s",
+// "3m") in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MatchRules are the tunable numbers of a match. Simple mode uses the turn
+// settings, enhanced mode the real-time ones.
+type MatchRules struct {
+	StartingMana       int      `json:"starting_mana"`
+	MaxMana            int      `json:"max_mana"`
+	ManaPerTurn        int      `json:"mana_per_turn"`  // simple
+	TurnTime           Duration `json:"turn_time"`      // simple
+	BoostCost          int      `json:"boost_cost"`     // simple
+	FortifyCost        int      `json:"fortify_cost"`   // simple
+	SwapCost           int      `json:"swap_cost"`      // simple
+	ManaRegen          Duration `json:"mana_regen"`     // enhanced, per mana point
+	MatchDuration      Duration `json:"match_duration"` // enhanced
+	StartDelay         Duration `json:"start_delay"`    // enhanced
+	Overtime           Duration `json:"overtime"`       // enhanced, 0 skips it
+	SuddenDeath        Duration `json:"sudden_death"`   // enhanced, 0 skips it
+	MatchmakingTimeout Duration `json:"matchmaking_timeout"`
+}
+
+var defaultRules = MatchRules{
+	StartingMana:       5,
+	MaxMana:            10,
+	ManaPerTurn:        3,
+	TurnTime:           Duration(30 * time.Second),
+	BoostCost:          2,
+	FortifyCost:        3,
+	SwapCost:           1,
+	ManaRegen:          Duration(2 * time.Second),
+	MatchDuration:      Duration(3 * time.Minute),
+	StartDelay:         Duration(3 * time.Second),
+	Overtime:           Duration(time.Minute),
+	SuddenDeath:        Duration(time.Minute),
+	MatchmakingTimeout: Duration(30 * time.Second),
+}
+
+// ==== RULES ====
+
+// ParseMatchRules resolves per-mode overrides against the defaults.
+// Settings a mode leaves out keep their default.
+func ParseMatchRules(raw map[string]json.RawMessage) (map[string]MatchRules, error) {
+	rules := map[string]MatchRules{"simple": defaultRules, "enhanced": defaultRules}
+	for mode, overrides := range raw {
+		r, err := defaultRules.WithOverrides(overrides)
+		if err != nil {
+			return rules, fmt.Errorf("%s: %w", mode, err)
+		}
+		rules[mode] = r
+	}
+	return rules, nil
+}
+
+// WithOverrides returns a copy of the rules with the settings present in
+// the JSON object replaced, as used by private rooms and events.
+func (r MatchRules) WithOverrides(overrides json.RawMessage) (MatchRules, error) {
+	if len(overrides) == 0 || string(overrides) == "null" {
+		return r, nil
+	}
+	if err := json.Unmarshal(overrides, &r); err != nil {
+		return r, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+	return r, r.Validate()
+}
+
+func (r MatchRules) Validate() error {
+	switch {
+	case r.MaxMana < 1 || r.MaxMana > 20:
+		return fmt.Errorf("%w: max_mana must be between 1 and 20", ErrInvalidRules)
+	case r.StartingMana < 0 || r.StartingMana > r.MaxMana:
+		return fmt.Errorf("%w: starting_mana must be between 0 and max_mana", ErrInvalidRules)
+	case r.ManaPerTurn < 0 || r.ManaPerTurn > r.MaxMana:
+		return fmt.Errorf("%w: mana_per_turn must be between 0 and max_mana", ErrInvalidRules)
+	case r.BoostCost < 0 || r.BoostCost > r.MaxMana:
+		return fmt.Errorf("%w: boost_cost must be between 0 and max_mana", ErrInvalidRules)
+	case r.FortifyCost < 0 || r.FortifyCost > r.MaxMana:
+		return fmt.Errorf("%w: fortify_cost must be between 0 and max_mana", ErrInvalidRules)
+	case r.SwapCost < 0 || r.SwapCost > r.MaxMana:
+		return fmt.Errorf("%w: swap_cost must be between 0 and max_mana", ErrInvalidRules)
+	case time.Duration(r.TurnTime) < 5*time.Second || time.Duration(r.TurnTime) > 2*time.Minute:
+		return fmt.Errorf("%w: turn_time must be between 5s and 2m", ErrInvalidRules)
+	case time.Duration(r.ManaRegen) < 100*time.Millisecond || time.Duration(r.ManaRegen) > 10*time.Second:
+		return fmt.Errorf("%w: mana_regen must be between 100ms and 10s", ErrInvalidRules)
+	case time.Duration(r.MatchDuration) < 30*time.Second || time.Duration(r.MatchDuration) > 15*time.Minute:
+		return fmt.Errorf("%w: match_duration must be between 30s and 15m", ErrInvalidRules)
+	case r.StartDelay < 0 || time.Duration(r.StartDelay) > 10*time.Second:
+		return fmt.Errorf("%w: start_delay must be at most 10s", ErrInvalidRules)
+	case r.Overtime < 0 || time.Duration(r.Overtime) > 5*time.Minute:
+		return fmt.Errorf("%w: overtime must be at most 5m", ErrInvalidRules)
+	case r.SuddenDeath < 0 || time.Duration(r.SuddenDeath) > 5*time.Minute:
+		return fmt.Errorf("%w: sudden_death must be at most 5m", ErrInvalidRules)
+	case time.Duration(r.MatchmakingTimeout) < 5*time.Second || time.Duration(r.MatchmakingTimeout) > 5*time.Minute:
+		return fmt.Errorf("%w: matchmaking_timeout must be between 5s and 5m", ErrInvalidRules)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// internal/model/season.go
+
+package model
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SeasonLength = 28 * 24 * time.Hour
+	// Trophies above this are halved at the end of a season.
+	seasonResetFloor = 1000
+	// Gold paid per arena reached when a season ends.
+	seasonGoldPerArena = 150
+)
+
+var (
+	seasonFile = "assets/data/season.json"
+	seasonMu   sync.Mutex
+)
+
+// ==== STRUCTS ====
+
+type Season struct {
+	Number    int       `json:"number"`
+	StartedAt time.Time `json:"startedAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	// Paying is the ended season whose rewards are still being paid.
+	Paying int `json:"paying,omitempty"`
+}
+
+// SeasonResult is what a user finished a season with and was given for it.
+type SeasonResult struct {
+	Season   int    `json:"season"`
+	Trophies int    `json:"trophies"`
+	Arena    string `json:"arena"`
+	Gold     int    `json:"gold"`
+	ResetTo  int    `json:"reset_to"`
+}
+
+// ==== SEASONS ====
+
+// CurrentSeason returns the running season, starting season 1 on first use.
+func CurrentSeason() (Season, error) {
+	seasonMu.Lock()
+	defer seasonMu.Unlock()
+	return loadSeason()
+}
+
+func loadSeason() (Season, error) {
+	data, err := os.ReadFile(seasonFile)
+	if os.IsNotExist(err) {
+		now := time.Now()
+		season := Season{Number: 1, StartedAt: now, EndsAt: now.Add(SeasonLength)}
+		return season, saveSeason(season)
+	}
+	if err != nil {
+		return Season{}, err
+	}
+
+	var season Season
+	if err := json.Unmarshal(data, &season); err != nil {
+		return Season{}, err
+	}
+	return season, nil
+}
+
+func saveSeason(season Season) error {
+	data, err := json.MarshalIndent(season, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(seasonFile, data, 0644)
+}
+
+// EndSeasonIfDue closes the current season once its end time has passed:
+// every ranked user is paid by arena, trophies above the floor are halved,
+// and the next season starts. It returns the per-user results, or nil if
+// the season is still running.
+//
+// The next season is saved before anyone is paid, marked as still paying
+// the last one, so a crash in between resumes the payout instead of ending
+// the season again.
+func EndSeasonIfDue(now time.Time) (Season, map[string]SeasonResult, error) {
+	seasonMu.Lock()
+	defer seasonMu.Unlock()
+
+	season, err := loadSeason()
+	if err != nil {
+		return season, nil, err
+	}
+	if season.Paying == 0 {
+		if now.Before(season.EndsAt) {
+			return season, nil, nil
+		}
+
+		// Seasons keep a fixed cadence even if the server was down at the end
+		next := Season{Number: season.Number + 1, StartedAt: season.EndsAt, EndsAt: season.EndsAt.Add(SeasonLength)}
+		for !now.Before(next.EndsAt) {
+			next.StartedAt, next.EndsAt = next.EndsAt, next.EndsAt.Add(SeasonLength)
+		}
+		next.Paying = season.Number
+		if err := saveSeason(next); err != nil {
+			return season, nil, err
+		}
+		season = next
+	}
+
+	results, err := paySeason(season.Paying, now)
+	if err != nil {
+		return season, nil, err
+	}
+	season.Paying = 0
+	if err := saveSeason(season); err != nil {
+		return season, results, err
+	}
+	return season, results, nil
+}
+
+// paySeason rewards every ranked user not yet paid for season number and
+// resets their trophies.
+func paySeason(number int, now time.Time) (map[string]SeasonResult, error) {
+	results := make(map[string]SeasonResult)
+	balances := make(map[string]int)
+	err := ModifyUsers(func(byName map[string]*User) error {
+		for _, u := range byName {
+			if u.Trophies == 0 || (u.LastSeason != nil && u.LastSeason.Season >= number) {
+				continue
+			}
+
+			arena := ArenaFor(u.Trophies)
+			result := SeasonResult{
+				Season:   number,
+				Trophies: u.Trophies,
+				Arena:    arena.Name,
+				Gold:     arena.Index * seasonGoldPerArena,
+				ResetTo:  u.Trophies,
+			}
+			if u.Trophies > seasonResetFloor {
+				result.ResetTo = seasonResetFloor + (u.Trophies-seasonResetFloor)/2
+			}
+
+			u.Gold += result.Gold
+			u.Trophies = result.ResetTo
+			u.LastSeason = &result
+			results[u.Username] = result
+			balances[u.Username] = u.Gold
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for username, r := range results {
+		if r.Gold > 0 {
+			RecordLedger(LedgerEntry{
+				Time:     now,
+				Username: username,
+				Action:   "season_reward",
+				Ref:      "season:" + strconv.Itoa(r.Season),
+				Gold:     r.Gold,
+				Balance:  balances[username],
+			})
+		}
+	}
+	return results, nil
+}
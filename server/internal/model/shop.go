@@ -0,0 +1,183 @@
+// internal/model/shop.go
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrOfferNotFound  = errors.New("offer not found")
+	ErrOfferPurchased = errors.New("offer already purchased today")
+)
+
+// Rarity of each daily shop slot; a new set is drawn every UTC day.
+var shopSlots = []string{"common", "common", "rare", "epic"}
+
+var cardPrice = map[string]int{
+	"common":    100,
+	"rare":      300,
+	"epic":      800,
+	"legendary": 2000,
+	"champion":  4000,
+}
+
+// ==== STRUCTS ====
+
+type ShopOffer struct {
+	ID     string `json:"id"`
+	Card   string `json:"card"`
+	Rarity string `json:"rarity"`
+	Image  string `json:"image"`
+	Price  int    `json:"price"`
+}
+
+// CardGrant describes what a card reward did to the collection.
+type CardGrant struct {
+	Card  string `json:"card"`
+	Level int    `json:"level"`
+	New   bool   `json:"new"`
+	Gold  int    `json:"gold,omitempty"` // paid instead when the card is maxed
+}
+
+// ==== DAILY OFFERS ====
+
+func ShopDay(now time.Time) string {
+	return now.UTC().Format("2006-01-02")
+}
+
+// NextShopReset is when the current daily offers rotate out.
+func NextShopReset(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// DailyOffers draws the day's offers. The draw is seeded by the date, so
+// every player and every server restart sees the same rotation.
+func DailyOffers(now time.Time, templates []Troop) []ShopOffer {
+	day := ShopDay(now)
+	rng := dayRand(now, "shop")
+
+	byRarity := make(map[string][]Troop)
+	for _, t := range templates {
+		byRarity[t.Rarity] = append(byRarity[t.Rarity], t)
+	}
+
+	offers := make([]ShopOffer, 0, len(shopSlots))
+	picked := make(map[string]bool)
+	for i, rarity := range shopSlots {
+		pool := byRarity[rarity]
+		if len(pool) == 0 {
+			continue
+		}
+
+		t := pool[rng.Intn(len(pool))]
+		for tries := 0; picked[t.Name] && tries < len(pool); tries++ {
+			t = pool[rng.Intn(len(pool))]
+		}
+		picked[t.Name] = true
+
+		offers = append(offers, ShopOffer{
+			ID:     fmt.Sprintf("%s-%d", day, i),
+			Card:   t.Name,
+			Rarity: rarity,
+			Image:  t.Image,
+			Price:  cardPrice[rarity],
+		})
+	}
+	return offers
+}
+
+// PurchasedToday lists the offer IDs the user bought in today's rotation.
+func (u *User) PurchasedToday(now time.Time) []string {
+	if u.ShopDay != ShopDay(now) {
+		return nil
+	}
+	return u.ShopBought
+}
+
+// BuyOffer charges the user for one of today's offers and grants its card,
+// as a single atomic update of the user store.
+func BuyOffer(username, offerID string) (User, ShopOffer, CardGrant, error) {
+	templates, err := LoadTroop()
+	if err != nil {
+		return User{}, ShopOffer{}, CardGrant{}, err
+	}
+
+	now := time.Now()
+	var offer ShopOffer
+	found := false
+	for _, o := range DailyOffers(now, templates) {
+		if o.ID == offerID {
+			offer, found = o, true
+			break
+		}
+	}
+	if !found {
+		return User{}, ShopOffer{}, CardGrant{}, ErrOfferNotFound
+	}
+
+	var grant CardGrant
+	user, err := UpdateUser(username, func(u *User) error {
+		u.UnlockCards(templates)
+
+		day := ShopDay(now)
+		if u.ShopDay != day {
+			u.ShopDay = day
+			u.ShopBought = nil
+		}
+		for _, id := range u.ShopBought {
+			if id == offer.ID {
+				return ErrOfferPurchased
+			}
+		}
+		if u.Cards[offer.Card] >= MaxCardLevel {
+			return ErrCardMaxLevel
+		}
+		if u.Gold < offer.Price {
+			return ErrNotEnoughGold
+		}
+
+		u.Gold -= offer.Price
+		u.ShopBought = append(u.ShopBought, offer.ID)
+		grant = u.grantCard(offer.Card, offer.Rarity)
+		return nil
+	})
+	if err != nil {
+		return User{}, ShopOffer{}, CardGrant{}, err
+	}
+
+	RecordLedger(LedgerEntry{
+		Username: username,
+		Action:   "shop_purchase",
+		Ref:      offer.ID,
+		Gold:     -offer.Price,
+		Cards:    map[string]int{grant.Card: grant.Level},
+		Balance:  user.Gold,
+	})
+	return user, offer, grant, nil
+}
+
+// grantCard adds a card to the collection, or raises its level if already
+// owned. Maxed cards are paid out as a quarter of their shop price.
+func (u *User) grantCard(name, rarity string) CardGrant {
+	if u.Cards == nil {
+		u.Cards = make(map[string]int)
+	}
+
+	level, owned := u.Cards[name]
+	switch {
+	case !owned:
+		u.Cards[name] = 1
+		return CardGrant{Card: name, Level: 1, New: true}
+	case level >= MaxCardLevel:
+		gold := cardPrice[rarity] / 4
+		u.Gold += gold
+		return CardGrant{Card: name, Level: level, Gold: gold}
+	default:
+		u.Cards[name] = level + 1
+		return CardGrant{Card: name, Level: level + 1}
+	}
+}
@@ -0,0 +1,79 @@
+// internal/model/snapshot.go
+
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var snapshotsDir = "assets/data/snapshots"
+
+// SaveSnapshot durably replaces the stored snapshot of a room.
+func SaveSnapshot(roomID string, data []byte) error {
+	if err := os.MkdirAll(snapshotsDir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(snapshotsDir, roomID+".json"), data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so a crash mid-write keeps the previous contents.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func DeleteSnapshot(roomID string) error {
+	err := os.Remove(filepath.Join(snapshotsDir, roomID+".json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// LoadSnapshots returns every stored snapshot by room ID.
+func LoadSnapshots() (map[string][]byte, error) {
+	entries, err := os.ReadDir(snapshotsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string][]byte)
+	for _, e := range entries {
+		roomID, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(snapshotsDir, e.Name()))
+		if err != nil {
+			return snapshots, err
+		}
+		snapshots[roomID] = data
+	}
+	return snapshots, nil
+}
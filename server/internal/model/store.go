@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 )
 
@@ -18,6 +21,53 @@ var (
 	usersStorageLock   = &sync.Mutex{}
 )
 
+var (
+	towersFile = "assets/data/towers.json"
+	troopsFile = "assets/data/troops.json"
+)
+
+// SetDataDir points every data file at dir. It must be called before the
+// first read, normally from the server config at startup.
+func SetDataDir(dir string) {
+	usersFile = filepath.Join(dir, "users.json")
+	towersFile = filepath.Join(dir, "towers.json")
+	troopsFile = filepath.Join(dir, "troops.json")
+	ledgerFile = filepath.Join(dir, "ledger.jsonl")
+	matchesFile = filepath.Join(dir, "matches.jsonl")
+	replaysDir = filepath.Join(dir, "replays")
+	seasonFile = filepath.Join(dir, "season.json")
+	snapshotsDir = filepath.Join(dir, "snapshots")
+	reportsFile = filepath.Join(dir, "reports.json")
+}
+
+// CheckStorage verifies that the data directory can be written to.
+func CheckStorage() error {
+	probe, err := os.CreateTemp(filepath.Dir(usersFile), ".probe-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(probe.Name())
+
+	if _, err := probe.WriteString("ok"); err != nil {
+		probe.Close()
+		return err
+	}
+	return probe.Close()
+}
+
+// Flush waits for user, match history and ledger writes in progress to
+// finish. Every store writes through to disk, so nothing else is buffered.
+func Flush() {
+	usersStorageLock.Lock()
+	usersStorageLock.Unlock()
+
+	matchesMu.Lock()
+	matchesMu.Unlock()
+
+	ledgerMu.Lock()
+	ledgerMu.Unlock()
+}
+
 // InitStorage ensures the data directory exists
 func InitStorage() error {
 	dir := filepath.Dir(usersFile)
@@ -39,6 +89,66 @@ func LoadUsers() ([]User, error) {
 	usersStorageLock.Lock()
 	defer usersStorageLock.Unlock()
 
+	return loadUsers()
+}
+
+// SaveUsers persists users to storage
+func SaveUsers(users []User) error {
+	usersStorageLock.Lock()
+	defer usersStorageLock.Unlock()
+
+	return saveUsers(users)
+}
+
+// ModifyUsers loads every user, lets fn change them by username and saves
+// the result, holding the storage lock throughout so concurrent updates
+// cannot overwrite each other. Users fn adds to the map are stored after
+// the existing ones. Nothing is saved if fn returns an error.
+func ModifyUsers(fn func(byName map[string]*User) error) error {
+	usersStorageLock.Lock()
+	defer usersStorageLock.Unlock()
+
+	users, err := loadUsers()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*User, len(users))
+	for i := range users {
+		byName[users[i].Username] = &users[i]
+	}
+	stored := maps.Clone(byName)
+
+	if err := fn(byName); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		if stored[name] == nil {
+			users = append(users, *byName[name])
+		}
+	}
+	return saveUsers(users)
+}
+
+// UpdateUser atomically applies fn to a single stored user and returns
+// the saved copy.
+func UpdateUser(username string, fn func(u *User) error) (User, error) {
+	var updated User
+	err := ModifyUsers(func(byName map[string]*User) error {
+		u, ok := byName[username]
+		if !ok {
+			return ErrUserNotFound
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+		updated = *u
+		return nil
+	})
+	return updated, err
+}
+
+func loadUsers() ([]User, error) {
 	if err := InitStorage(); err != nil {
 		return nil, err
 	}
@@ -54,16 +164,16 @@ func LoadUsers() ([]User, error) {
 	return users, err
 }
 
-// SaveUsers persists users to storage
-func SaveUsers(users []User) error {
-	usersStorageLock.Lock()
-	defer usersStorageLock.Unlock()
-
+func saveUsers(users []User) error {
 	data, err := json.MarshalIndent(users, "", "  ")
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(usersFile, data, 0644)
+	if err := writeFileAtomic(usersFile, data); err != nil {
+		return err
+	}
+	refreshLeaderboard(users)
+	return nil
 }
 
 func SaveUser(user *User) error {
@@ -88,22 +198,41 @@ func SaveUser(user *User) error {
     return SaveUsers(users)
 }
 
-// AddUser adds a new user if the username is unique
-func AddUser(newUser User) error {
-	users, err := LoadUsers()
+// SaveProgress adds a match to the stored user: the EXP and gold earned in
+// it and a game played, and won if won. Only these deltas are applied, so
+// anything that changed elsewhere while the match was running, such as
+// quest EXP, card levels or gold spent in the shop, is kept. Cards unlocked
+// by a new user level are added to the collection.
+func SaveProgress(username string, exp int, won bool, goldEarned int) error {
+	templates, err := LoadTroop()
 	if err != nil {
 		return err
 	}
 
-	// Check if the username already exists
-	for _, u := range users {
-		if u.Username == newUser.Username {
-			return ErrUserExists
+	_, err = UpdateUser(username, func(u *User) error {
+		u.AddExp(exp)
+		u.GamesPlayed++
+		if won {
+			u.GamesWon++
 		}
-	}
+		u.Gold += goldEarned
+		if unlocked := u.UnlockCards(templates); len(unlocked) > 0 {
+			log.Printf("[INFO][COLLECTION] %s unlocked %v", u.Username, unlocked)
+		}
+		return nil
+	})
+	return err
+}
 
-	users = append(users, newUser)
-	return SaveUsers(users)
+// AddUser adds a new user if the username is unique
+func AddUser(newUser User) error {
+	return ModifyUsers(func(byName map[string]*User) error {
+		if _, exists := byName[newUser.Username]; exists {
+			return ErrUserExists
+		}
+		byName[newUser.Username] = &newUser
+		return nil
+	})
 }
 
 // FindUserByUsername retrieves a user by username
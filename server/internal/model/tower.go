@@ -21,6 +21,8 @@ type Tower struct {
 	EXP         int     `json:"exp"`
 	Range       float64 `json:"range"`
 	AttackSpeed float64 `json:"attack_speed"`
+
+	FortifiedDEF float64 `json:"fortified_def,omitempty"` // DEF added by a fortify in simple mode
 }
 
 type Area struct {
@@ -59,7 +61,7 @@ func (t *TowerInstance) IsAlive() bool {
 // ---------- Initialization ----------
 
 func LoadTower() map[string]*Tower {
-	file, err := os.Open("assets/data/towers.json")
+	file, err := os.Open(towersFile)
 	if err != nil {
 		return nil
 	}
@@ -172,6 +174,26 @@ func (t *Tower) TakeDamage(rawAtk float64, attackerLevel int) (float64, bool) {
 	return dmg, t.HP == 0
 }
 
+// FortifyBonus is the share of its DEF a fortified tower gains.
+const FortifyBonus = 0.5
+
+// Fortify raises the tower's DEF until EndFortify.
+func (t *Tower) Fortify() {
+	t.FortifiedDEF = t.DEF * FortifyBonus
+	t.DEF += t.FortifiedDEF
+}
+
+// Fortified reports whether the tower is fortified.
+func (t *Tower) Fortified() bool {
+	return t.FortifiedDEF > 0
+}
+
+// EndFortify takes the tower back to its normal DEF.
+func (t *Tower) EndFortify() {
+	t.DEF -= t.FortifiedDEF
+	t.FortifiedDEF = 0
+}
+
 func (t *Tower) Heal(amount float64) {
 	t.HP += amount
 	if t.HP > t.MaxHP {
@@ -185,16 +207,3 @@ func (a Area) Contains(pos Position) bool {
 	return pos.X >= a.TopLeft.X && pos.X <= a.BottomRight.X &&
 		pos.Y >= a.TopLeft.Y && pos.Y <= a.BottomRight.Y
 }
-
-func GetLowestHPTower(player *Player) *Tower {
-	var lowest *Tower
-	for _, tower := range player.Towers {
-		if tower.HP <= 0 {
-			continue
-		}
-		if lowest == nil || tower.HP < lowest.HP {
-			lowest = tower
-		}
-	}
-	return lowest
-}
@@ -32,6 +32,8 @@ type Troop struct {
 	AttackSpeed   float64 `json:"attack_speed"`
 	AggroPriority string  `json:"aggro_priority"`
 	Rarity        string  `json:"rarity"`
+	Level         int     `json:"level,omitempty"`   // card level, set when dealt from a collection
+	Boosted       bool    `json:"boosted,omitempty"` // simple mode, until its next attack
 }
 
 type Position struct {
@@ -71,7 +73,7 @@ func (p Position) String() string {
 
 // Load troop templates from JSON file
 func LoadTroop() ([]Troop, error) {
-	file, err := os.Open("assets/data/troops.json")
+	file, err := os.Open(troopsFile)
 	if err != nil {
 		return nil, err
 	}
@@ -100,25 +102,56 @@ func shuffleTroops(troops []*Troop) []*Troop {
 	return shuffled
 }
 
-// Get random n troops, HP reset to MaxHP
-func getRandomTroops(n int) []*Troop {
+// Get n random troops from the user's collection, scaled to card level
+func getOwnedTroops(user *User, n int) []*Troop {
 	templates, err := LoadTroop()
 	if err != nil {
 		return nil
 	}
 
-	shuffled := shuffleTroops(pointerizeTroops(templates))
+	// Legacy accounts without a collection get their unlocked cards here;
+	// the collection is persisted with the match progress.
+	user.UnlockCards(templates)
+
+	shuffled := shuffleTroops(user.OwnedTroops(templates))
 	if n > len(shuffled) {
 		n = len(shuffled)
 	}
+	return shuffled[:n]
+}
 
-	selected := make([]*Troop, n)
-	for i := 0; i < n; i++ {
-		t := *shuffled[i] // copy struct
-		t.HP = t.MaxHP    // reset HP full
-		selected[i] = &t
+// Get the troops of a saved deck from the user's collection, scaled to card
+// level and shuffled. Falls back to random owned troops without a deck.
+func getDeckTroops(user *User, deck []string, n int) []*Troop {
+	if len(deck) == 0 {
+		return getOwnedTroops(user, n)
 	}
-	return selected
+
+	templates, err := LoadTroop()
+	if err != nil {
+		return nil
+	}
+
+	byName := make(map[string]Troop, len(templates))
+	for _, t := range templates {
+		byName[t.Name] = t
+	}
+
+	troops := make([]*Troop, 0, len(deck))
+	for _, name := range deck {
+		t, ok := byName[name]
+		if !ok {
+			continue
+		}
+		t.ApplyLevel(user.CardLevel(name))
+		troops = append(troops, &t)
+	}
+
+	shuffled := shuffleTroops(troops)
+	if n > len(shuffled) {
+		n = len(shuffled)
+	}
+	return shuffled[:n]
 }
 
 // helper to convert slice of Troop structs to slice of pointers
@@ -190,9 +223,21 @@ func (t *Troop) CalculateHeal(level int) (float64, bool) {
 	return baseHeal, isCrit
 }
 
-// Boost attack by 50%
+// BoostMultiplier is how much a boost raises a troop's ATK.
+const BoostMultiplier = 1.5
+
+// Boost attack by 50% until EndBoost
 func (t *Troop) BoostAttack() {
-	t.ATK *= 1.5
+	t.ATK *= BoostMultiplier
+	t.Boosted = true
+}
+
+// EndBoost takes a boosted troop back to its normal ATK.
+func (t *Troop) EndBoost() {
+	if t.Boosted {
+		t.ATK /= BoostMultiplier
+		t.Boosted = false
+	}
 }
 
 // Heal (Fortify HP) with cap at MaxHP
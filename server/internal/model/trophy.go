@@ -0,0 +1,103 @@
+// internal/model/trophy.go
+
+package model
+
+// ==== STRUCTS ====
+
+type Arena struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	MinTrophies int    `json:"min_trophies"`
+}
+
+// TrophyChange is one player's ladder result for a match.
+type TrophyChange struct {
+	Trophies int   `json:"trophies"`
+	Delta    int   `json:"delta"`
+	Arena    Arena `json:"arena"`
+	NewArena bool  `json:"new_arena"` // moved to a different arena
+}
+
+var arenas = []Arena{
+	{Index: 0, Name: "Training Grounds", MinTrophies: 0},
+	{Index: 1, Name: "Goblin Hollow", MinTrophies: 300},
+	{Index: 2, Name: "Bone Crypt", MinTrophies: 600},
+	{Index: 3, Name: "Frost Keep", MinTrophies: 1000},
+	{Index: 4, Name: "Ember Forge", MinTrophies: 1500},
+	{Index: 5, Name: "Storm Spire", MinTrophies: 2000},
+	{Index: 6, Name: "Royal Citadel", MinTrophies: 2600},
+	{Index: 7, Name: "Legend's Summit", MinTrophies: 3200},
+}
+
+const (
+	baseTrophyGain = 30
+	minTrophyGain  = 15
+	maxTrophyGain  = 45
+)
+
+// ==== ARENAS ====
+
+func Arenas() []Arena {
+	return append([]Arena(nil), arenas...)
+}
+
+func ArenaFor(trophies int) Arena {
+	current := arenas[0]
+	for _, a := range arenas {
+		if trophies >= a.MinTrophies {
+			current = a
+		}
+	}
+	return current
+}
+
+// ==== LADDER ====
+
+// TrophyDelta returns how many trophies the winner gains and the loser
+// loses. Beating a higher-ranked opponent is worth more.
+func TrophyDelta(winnerTrophies, loserTrophies int) (int, int) {
+	gain := baseTrophyGain + (loserTrophies-winnerTrophies)/25
+	gain = max(minTrophyGain, min(maxTrophyGain, gain))
+	return gain, gain * 2 / 3
+}
+
+// ApplyTrophies updates both players' trophies for a finished match in one
+// atomic write. Draws leave the ladder unchanged.
+func ApplyTrophies(winner, loser string, isDraw bool) (map[string]TrophyChange, error) {
+	changes := make(map[string]TrophyChange, 2)
+
+	err := ModifyUsers(func(byName map[string]*User) error {
+		w, l := byName[winner], byName[loser]
+		if w == nil || l == nil {
+			return ErrUserNotFound
+		}
+
+		gain, loss := 0, 0
+		if !isDraw {
+			gain, loss = TrophyDelta(w.Trophies, l.Trophies)
+		}
+
+		changes[winner] = w.addTrophies(gain)
+		changes[loser] = l.addTrophies(-loss)
+		return nil
+	})
+	return changes, err
+}
+
+func (u *User) addTrophies(delta int) TrophyChange {
+	old := u.Trophies
+	before := ArenaFor(old)
+
+	u.Trophies = max(0, u.Trophies+delta)
+	if u.Trophies > u.BestTrophies {
+		u.BestTrophies = u.Trophies
+	}
+
+	after := ArenaFor(u.Trophies)
+	return TrophyChange{
+		Trophies: u.Trophies,
+		Delta:    u.Trophies - old,
+		Arena:    after,
+		NewArena: after.Index != before.Index,
+	}
+}
@@ -24,6 +24,29 @@ type User struct {
 	GamesWon    int       `json:"gamesWon"`    // Track number of games won
 	Avatar      string    `json:"avatar"`
 	Gold        int       `json:"gold"`
+
+	Friends        []string `json:"friends,omitempty"`
+	FriendRequests []string `json:"friendRequests,omitempty"` // incoming, by username
+
+	Cards       map[string]int    `json:"cards,omitempty"`       // card name -> card level
+	Decks       []Deck            `json:"decks,omitempty"`
+	ActiveDecks map[string]string `json:"activeDecks,omitempty"` // mode -> deck name
+
+	Chests     []Chest  `json:"chests,omitempty"`
+	ShopDay    string   `json:"shopDay,omitempty"`    // UTC day of ShopBought
+	ShopBought []string `json:"shopBought,omitempty"` // offer IDs bought that day
+
+	Quests       *QuestLog      `json:"quests,omitempty"`
+	Records      map[string]int `json:"records,omitempty"`      // lifetime metric counters
+	Achievements map[string]int `json:"achievements,omitempty"` // achievement ID -> tier reached
+
+	Trophies     int           `json:"trophies"`
+	BestTrophies int           `json:"bestTrophies,omitempty"`
+	LastSeason   *SeasonResult `json:"lastSeason,omitempty"`
+
+	Ban       *Ban      `json:"ban,omitempty"`
+	RenamedAt time.Time `json:"renamedAt,omitzero"` // last username change
+	Guest     bool      `json:"guest,omitempty"`    // unclaimed, without a password
 }
 
 func NewUser(username, password string) *User {
@@ -112,7 +135,7 @@ func GenerateLevels(maxLevel int) []Level {
 }
 
 func getRandomAvatar() int {
-	n, err := rand.Int(rand.Reader, big.NewInt(15)) // 0–14
+	n, err := rand.Int(rand.Reader, big.NewInt(randomAvatars)) // 0–14
 	if err != nil {
 		// fallback or panic, your call
 		return 1
@@ -0,0 +1,296 @@
+// internal/network/account.go
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"royaka/internal/model"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Account errors shared by the WebSocket and HTTP front-ends.
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrHashPassword       = errors.New("error hashing password")
+	ErrReadSessions       = errors.New("error reading sessions")
+	ErrSaveSession        = errors.New("error saving session")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrTooManySignups     = errors.New("too many registrations, try again later")
+)
+
+// Registration rules.
+const (
+	minUsername = 3
+	maxUsername = 16
+	minPassword = 8
+	maxPassword = 72 // bcrypt ignores anything longer
+)
+
+var (
+	ErrUsernameLength   = fmt.Errorf("username must be %d to %d characters", minUsername, maxUsername)
+	ErrUsernameChars    = errors.New("username must start with a letter and contain only letters, digits, _ and -")
+	ErrUsernameReserved = errors.New("username is reserved")
+	ErrPasswordLength   = fmt.Errorf("password must be %d to %d characters", minPassword, maxPassword)
+	ErrPasswordWeak     = errors.New("password must contain both letters and digits")
+	ErrPasswordCommon   = errors.New("password is too common or contains the username")
+
+	registrationErrors = []error{
+		ErrUsernameLength, ErrUsernameChars, ErrUsernameReserved,
+		ErrPasswordLength, ErrPasswordWeak, ErrPasswordCommon,
+	}
+)
+
+var (
+	reservedNames    = []string{"admin", "administrator", "moderator", "mod", "system", "server", "support", "staff", "root", "null", "undefined"}
+	reservedPrefixes = []string{"admin", "royaka", "guest"}
+	commonPasswords  = []string{"password1", "passw0rd", "qwerty123", "abc12345", "12345678a", "letmein1", "iloveyou1", "welcome1", "1q2w3e4r", "trustno1"}
+)
+
+// ValidateRegistration checks a new username and password against the
+// registration rules.
+func ValidateRegistration(username, password string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	return ValidatePassword(username, password)
+}
+
+func ValidateUsername(username string) error {
+	if len(username) < minUsername || len(username) > maxUsername {
+		return ErrUsernameLength
+	}
+	for i, r := range username {
+		letter := r <= unicode.MaxASCII && unicode.IsLetter(r)
+		if (i == 0 && !letter) || (!letter && !unicode.IsDigit(r) && r != '_' && r != '-') {
+			return ErrUsernameChars
+		}
+	}
+	name := strings.ToLower(username)
+	if slices.Contains(reservedNames, name) || slices.ContainsFunc(reservedPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(name, prefix)
+	}) {
+		return ErrUsernameReserved
+	}
+	return nil
+}
+
+// ValidatePassword checks a password for the given username.
+func ValidatePassword(username, password string) error {
+	if len(password) < minPassword || len(password) > maxPassword {
+		return ErrPasswordLength
+	}
+	if !strings.ContainsFunc(password, unicode.IsLetter) || !strings.ContainsFunc(password, unicode.IsDigit) {
+		return ErrPasswordWeak
+	}
+	lower := strings.ToLower(password)
+	if slices.Contains(commonPasswords, lower) || strings.Contains(lower, strings.ToLower(username)) {
+		return ErrPasswordCommon
+	}
+	return nil
+}
+
+// invalidRegistration reports whether err is a registration rule failing.
+func invalidRegistration(err error) bool {
+	return slices.ContainsFunc(registrationErrors, func(target error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// RegisterUser validates and hashes the password and stores a new user.
+// Each client IP can only create a few accounts in a row.
+func RegisterUser(ip, username, password string) error {
+	if err := ValidateRegistration(username, password); err != nil {
+		log.Printf("[WARN][AUTH] Registration of %q rejected: %v", username, err)
+		return fmt.Errorf("registration failed: %w", err)
+	}
+	if _, ok := registrations.allow(ip); !ok {
+		log.Printf("[WARN][AUTH] Registration of %q throttled for %s", username, ip)
+		return ErrTooManySignups
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("[ERROR][AUTH] Password hashing failed for %s: %v", username, err)
+		return ErrHashPassword
+	}
+
+	if err := model.AddUser(*model.NewUser(username, string(hashedPassword))); err != nil {
+		log.Printf("[WARN][AUTH] Registration failed for %s: %v", username, err)
+		return fmt.Errorf("registration failed: %w", err)
+	}
+
+	log.Printf("[INFO][AUTH] User %s registered successfully", username)
+	return nil
+}
+
+// LoginUser checks the credentials and stores a new session, returning its
+// ID. Repeated failures for the username or from the client IP make later
+// attempts wait, returning a ThrottledError.
+func (st *SessionStore) LoginUser(ip, username, password string) (string, error) {
+	if err := logins.check(ip, username); err != nil {
+		log.Printf("[WARN][AUTH] Login throttled for %s from %s", username, ip)
+		return "", err
+	}
+
+	u, ok := model.FindUserByUsername(username)
+	if !ok {
+		log.Printf("[WARN][AUTH] Login failed, user %s not found", username)
+		logins.failed(ip, username)
+		return "", ErrInvalidCredentials
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
+		log.Printf("[WARN][AUTH] Login failed, incorrect password for %s", username)
+		logins.failed(ip, username)
+		return "", ErrInvalidCredentials
+	}
+	logins.succeeded(username)
+
+	if u.Banned() {
+		log.Printf("[WARN][AUTH] Login refused, %s is banned", username)
+		return "", model.ErrBanned
+	}
+	if err := model.RecordLogin(username); err != nil {
+		log.Printf("[WARN][AUTH] Recording login of %s failed: %v", username, err)
+	}
+
+	sessionID := uuid.New().String()[:8]
+	session := Session{SessionID: sessionID, Username: username, Authenticated: true}
+	log.Printf("[INFO][AUTH] User %s authenticated", username)
+	sessions, err := st.ReadSessions()
+	if err != nil {
+		log.Printf("[ERROR][AUTH] Reading sessions failed: %v", err)
+		return "", ErrReadSessions
+	}
+
+	sessions = append(sessions, session)
+	if err := st.WriteSession(sessions); err != nil {
+		log.Printf("[ERROR][AUTH] Writing sessions failed: %v", err)
+		return "", ErrSaveSession
+	}
+
+	log.Printf("[INFO][AUTH] Session stored for user %s", username)
+	return sessionID, nil
+}
+
+// LogoutSession removes a session so its ID can no longer be used.
+func (st *SessionStore) LogoutSession(sessionID string) error {
+	sessions, err := st.ReadSessions()
+	if err != nil {
+		return ErrReadSessions
+	}
+
+	kept := sessions[:0]
+	found := false
+	for _, s := range sessions {
+		if s.SessionID == sessionID {
+			found = true
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if !found {
+		return ErrSessionNotFound
+	}
+
+	if err := st.WriteSession(kept); err != nil {
+		return ErrSaveSession
+	}
+
+	log.Printf("[INFO][AUTH] Session logged out")
+	return nil
+}
+
+// RenewSession replaces the user's sessions with a new one, so earlier
+// session IDs stop working, and returns its ID.
+func (st *SessionStore) RenewSession(username string) (string, error) {
+	sessions, err := st.ReadSessions()
+	if err != nil {
+		return "", ErrReadSessions
+	}
+
+	sessionID := uuid.New().String()[:8]
+	sessions = slices.DeleteFunc(sessions, func(s Session) bool { return s.Username == username })
+	sessions = append(sessions, Session{SessionID: sessionID, Username: username, Authenticated: true})
+	if err := st.WriteSession(sessions); err != nil {
+		return "", ErrSaveSession
+	}
+	return sessionID, nil
+}
+
+// RenameSessions moves the user's sessions to their new name.
+func (st *SessionStore) RenameSessions(oldName, newName string) error {
+	sessions, err := st.ReadSessions()
+	if err != nil {
+		return ErrReadSessions
+	}
+	for i := range sessions {
+		if sessions[i].Username == oldName {
+			sessions[i].Username = newName
+		}
+	}
+	if err := st.WriteSession(sessions); err != nil {
+		return ErrSaveSession
+	}
+	return nil
+}
+
+// DropSessions logs the user out everywhere.
+func (st *SessionStore) DropSessions(username string) error {
+	sessions, err := st.ReadSessions()
+	if err != nil {
+		return ErrReadSessions
+	}
+	sessions = slices.DeleteFunc(sessions, func(s Session) bool { return s.Username == username })
+	if err := st.WriteSession(sessions); err != nil {
+		return ErrSaveSession
+	}
+	return nil
+}
+
+// CheckPassword verifies the user's current password for account changes,
+// counting failures like failed logins.
+func CheckPassword(ip string, user model.User, password string) error {
+	if err := logins.check(ip, user.Username); err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		logins.failed(ip, user.Username)
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// UserBySession resolves a session ID to the user it belongs to.
+func (st *SessionStore) UserBySession(sessionID string) (model.User, error) {
+	session, err := st.FindSessionByID(sessionID)
+	if err != nil {
+		log.Printf("[WARN][AUTH] Session not found")
+		return model.User{}, ErrSessionNotFound
+	}
+
+	user, ok := model.FindUserByUsername(session.Username)
+	if !ok {
+		log.Printf("[WARN][AUTH] User %s from session not found", session.Username)
+		return model.User{}, model.ErrUserNotFound
+	}
+	if user.Banned() {
+		return model.User{}, model.ErrBanned
+	}
+
+	// Using the session keeps a guest from being cleaned up
+	if user.Guest {
+		if err := model.RecordLogin(user.Username); err != nil {
+			log.Printf("[WARN][AUTH] Recording login of %s failed: %v", user.Username, err)
+		}
+	}
+
+	return user, nil
+}
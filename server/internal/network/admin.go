@@ -0,0 +1,374 @@
+// internal/network/admin.go
+
+package network
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"royaka/internal/game"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+)
+
+const maxAnnouncement = 500
+
+// RegisterHealth mounts the load balancer probes. /healthz only says the
+// process is serving; /readyz also checks that storage is writable and
+// that the server is not shutting down.
+func RegisterHealth(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeAPI(w, http.StatusOK, "health", "ok", nil)
+	})
+	mux.HandleFunc("GET /readyz", apiReady)
+}
+
+func apiReady(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{"storage": "ok", "matchmaking": "ok"}
+	status := http.StatusOK
+
+	if err := model.CheckStorage(); err != nil {
+		log.Printf("[WARN][HEALTH] Storage not writable: %v", err)
+		checks["storage"] = "not writable"
+		status = http.StatusServiceUnavailable
+	}
+	if game.Draining() {
+		checks["matchmaking"] = "shutting down"
+		status = http.StatusServiceUnavailable
+	}
+
+	message := "ready"
+	if status != http.StatusOK {
+		message = "not ready"
+	}
+	writeAPI(w, status, "ready", message, checks)
+}
+
+// RegisterAdmin mounts the operator API under /admin/. Every route needs
+// "Authorization: Bearer <admin_token>"; without a configured token the
+// API is not served.
+func RegisterAdmin(mux *http.ServeMux, token string) {
+	if token == "" {
+		return
+	}
+
+	admin := http.NewServeMux()
+	admin.HandleFunc("GET /admin/rooms", adminRooms)
+	admin.HandleFunc("POST /admin/rooms/{id}/end", adminEndMatch)
+	admin.HandleFunc("GET /admin/queues", adminQueues)
+	admin.HandleFunc("POST /admin/users/{username}/kick", adminKick)
+	admin.HandleFunc("POST /admin/users/{username}/ban", adminBan)
+	admin.HandleFunc("DELETE /admin/users/{username}/ban", adminUnban)
+	admin.HandleFunc("POST /admin/users/{username}/suspend", adminSuspend)
+	admin.HandleFunc("DELETE /admin/users/{username}/suspend", adminUnban)
+	admin.HandleFunc("GET /admin/reports", adminReports)
+	admin.HandleFunc("POST /admin/reports/{id}/resolve", adminResolveReport)
+	admin.HandleFunc("GET /admin/matches/{id}/replay", adminReplay)
+	admin.HandleFunc("POST /admin/announce", adminAnnounce)
+
+	mux.Handle("/admin/", requireAdmin(token, admin))
+}
+
+func requireAdmin(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := bearerToken(r)
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			log.Printf("[WARN][ADMIN] Rejected %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			writeAPI(w, http.StatusUnauthorized, "admin_response", "Admin token required", nil)
+			return
+		}
+		log.Printf("[INFO][ADMIN] %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func adminRooms(w http.ResponseWriter, r *http.Request) {
+	writeAPI(w, http.StatusOK, "admin_rooms", "", game.RoomSummaries())
+}
+
+func adminEndMatch(w http.ResponseWriter, r *http.Request) {
+	var req utils.EndMatchRequest
+	if !decodeOptional(w, r, &req, "admin_end_match") {
+		return
+	}
+
+	err := game.ForceEnd(r.PathValue("id"), req.Winner)
+	switch {
+	case errors.Is(err, game.ErrRoomNotFound):
+		writeAPI(w, http.StatusNotFound, "admin_end_match", "Room not found", nil)
+	case errors.Is(err, game.ErrMatchOver):
+		writeAPI(w, http.StatusConflict, "admin_end_match", "Match already ended", nil)
+	case errors.Is(err, game.ErrNotInRoom):
+		writeAPI(w, http.StatusBadRequest, "admin_end_match", "Winner is not a player in this room", nil)
+	default:
+		writeAPI(w, http.StatusOK, "admin_end_match", "Match ended", nil)
+	}
+}
+
+func adminQueues(w http.ResponseWriter, r *http.Request) {
+	writeAPI(w, http.StatusOK, "admin_queues", "", game.QueueContents())
+}
+
+func adminKick(w http.ResponseWriter, r *http.Request) {
+	var req utils.KickRequest
+	if !decodeOptional(w, r, &req, "admin_kick") {
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "Disconnected by an administrator"
+	}
+
+	if err := game.KickUser(r.PathValue("username"), req.Reason); err != nil {
+		writeAPI(w, http.StatusNotFound, "admin_kick", "User is not connected", nil)
+		return
+	}
+	writeAPI(w, http.StatusOK, "admin_kick", "User kicked", nil)
+}
+
+func adminBan(w http.ResponseWriter, r *http.Request) {
+	adminRestrict(w, r, model.BanKindBan, "admin_ban")
+}
+
+func adminSuspend(w http.ResponseWriter, r *http.Request) {
+	adminRestrict(w, r, model.BanKindSuspension, "admin_suspend")
+}
+
+func adminRestrict(w http.ResponseWriter, r *http.Request, kind, responseType string) {
+	var req utils.BanRequest
+	if !decodeOptional(w, r, &req, responseType) {
+		return
+	}
+	duration, ok := banDuration(req.Duration, kind)
+	if !ok {
+		writeAPI(w, http.StatusBadRequest, responseType, "Invalid duration", nil)
+		return
+	}
+
+	ban, err := restrict(r.PathValue("username"), kind, req.Reason, duration)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		writeAPI(w, status, responseType, "Failed: "+err.Error(), nil)
+		return
+	}
+	writeAPI(w, http.StatusOK, responseType, "User "+restrictedWord(kind), ban)
+}
+
+// restrict bans or suspends a user and acts on their connection: banned
+// users are kicked, suspended ones are told and leave matchmaking.
+func restrict(username, kind, reason string, duration time.Duration) (model.Ban, error) {
+	restrictFn := model.BanUser
+	if kind == model.BanKindSuspension {
+		restrictFn = model.SuspendUser
+	}
+	ban, err := restrictFn(username, reason, "admin", duration)
+	if err != nil {
+		return ban, err
+	}
+
+	log.Printf("[INFO][ADMIN] Restricted %s (%s, %s): %s", username, kind, banLength(ban), reason)
+	if kind == model.BanKindSuspension {
+		game.NotifySuspension(username, ban)
+	} else {
+		game.KickUser(username, game.RestrictionMessage(&ban))
+	}
+	return ban, nil
+}
+
+func adminUnban(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+	if err := model.UnbanUser(username); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		writeAPI(w, status, "admin_unban", "Unban failed: "+err.Error(), nil)
+		return
+	}
+
+	log.Printf("[INFO][ADMIN] Lifted restrictions on %s", username)
+	writeAPI(w, http.StatusOK, "admin_unban", "Restrictions lifted", nil)
+}
+
+// ==== REPORTS ====
+
+// reportView adds where to fetch the match replay, when one was kept.
+type reportView struct {
+	model.Report
+	ReplayURL string `json:"replay_url,omitempty"`
+}
+
+// adminReports lists reports with ?status=open (default), resolved,
+// dismissed or all, oldest first.
+func adminReports(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = model.ReportOpen
+	case "all":
+		status = ""
+	case model.ReportOpen, model.ReportResolved, model.ReportDismissed:
+	default:
+		writeAPI(w, http.StatusBadRequest, "admin_reports", "Status must be open, resolved, dismissed or all", nil)
+		return
+	}
+
+	reports, err := model.Reports(status)
+	if err != nil {
+		log.Printf("[ERROR][ADMIN] Loading reports failed: %v", err)
+		writeAPI(w, http.StatusInternalServerError, "admin_reports", "Failed to load reports", nil)
+		return
+	}
+
+	views := make([]reportView, len(reports))
+	for i, report := range reports {
+		views[i].Report = report
+		if match, err := model.FindMatch(report.MatchID); err == nil && match.HasReplay {
+			views[i].ReplayURL = "/admin/matches/" + match.ID + "/replay"
+		}
+	}
+	writeAPI(w, http.StatusOK, "admin_reports", "", views)
+}
+
+// adminResolveReport closes a report, optionally suspending or banning the
+// reported player.
+func adminResolveReport(w http.ResponseWriter, r *http.Request) {
+	var req utils.ResolveReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPI(w, http.StatusBadRequest, "admin_resolve_report", "Invalid request body", nil)
+		return
+	}
+
+	report, err := model.FindReport(r.PathValue("id"))
+	switch {
+	case errors.Is(err, model.ErrReportNotFound):
+		writeAPI(w, http.StatusNotFound, "admin_resolve_report", "Report not found", nil)
+		return
+	case err != nil:
+		log.Printf("[ERROR][ADMIN] Loading report failed: %v", err)
+		writeAPI(w, http.StatusInternalServerError, "admin_resolve_report", "Failed to load report", nil)
+		return
+	case report.Status != model.ReportOpen:
+		writeAPI(w, http.StatusConflict, "admin_resolve_report", "Report already handled", nil)
+		return
+	}
+
+	status, action := model.ReportResolved, req.Action
+	switch req.Action {
+	case "dismiss":
+		status, action = model.ReportDismissed, ""
+	case "none":
+		action = ""
+	case "suspend", "ban":
+		kind := model.BanKindBan
+		if req.Action == "suspend" {
+			kind = model.BanKindSuspension
+		}
+		duration, ok := banDuration(req.Duration, kind)
+		if !ok {
+			writeAPI(w, http.StatusBadRequest, "admin_resolve_report", "Invalid duration", nil)
+			return
+		}
+		reason := req.Note
+		if reason == "" {
+			reason = "Reported for " + strings.ReplaceAll(report.Reason, "_", " ")
+		}
+		ban, err := restrict(report.Reported, kind, reason, duration)
+		if err != nil {
+			writeAPI(w, http.StatusInternalServerError, "admin_resolve_report", "Failed: "+err.Error(), nil)
+			return
+		}
+		action = kind + " " + banLength(ban)
+	default:
+		writeAPI(w, http.StatusBadRequest, "admin_resolve_report", "Action must be dismiss, none, suspend or ban", nil)
+		return
+	}
+
+	report, err = model.CloseReport(report.ID, status, action, req.Note, "admin")
+	if errors.Is(err, model.ErrReportNotOpen) {
+		writeAPI(w, http.StatusConflict, "admin_resolve_report", "Report already handled", nil)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR][ADMIN] Closing report %s failed: %v", report.ID, err)
+		writeAPI(w, http.StatusInternalServerError, "admin_resolve_report", "Failed to save report", nil)
+		return
+	}
+
+	log.Printf("[INFO][ADMIN] Report %s %s", report.ID, report.Status)
+	writeAPI(w, http.StatusOK, "admin_resolve_report", "Report "+report.Status, report)
+}
+
+func adminReplay(w http.ResponseWriter, r *http.Request) {
+	match, err := model.FindMatch(r.PathValue("id"))
+	if err != nil || !match.HasReplay {
+		writeAPI(w, http.StatusNotFound, "replay_response", "Replay not found", nil)
+		return
+	}
+	serveReplay(w, match)
+}
+
+func adminAnnounce(w http.ResponseWriter, r *http.Request) {
+	var req utils.AnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPI(w, http.StatusBadRequest, "admin_announce", "Invalid announcement", nil)
+		return
+	}
+
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Kind == "" {
+		req.Kind = "info"
+	}
+	switch {
+	case req.Message == "" || len(req.Message) > maxAnnouncement:
+		writeAPI(w, http.StatusBadRequest, "admin_announce", "Message must be 1 to 500 characters", nil)
+		return
+	case !game.ValidAnnouncementKind(req.Kind):
+		writeAPI(w, http.StatusBadRequest, "admin_announce", "Kind must be info, warning or maintenance", nil)
+		return
+	}
+
+	sent := game.Announce(req.Message, req.Kind)
+	writeAPI(w, http.StatusOK, "admin_announce", "Announcement sent", map[string]int{"recipients": sent})
+}
+
+// decodeOptional reads a JSON body into v; an empty body keeps the zero value.
+func decodeOptional(w http.ResponseWriter, r *http.Request, v any, responseType string) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeAPI(w, http.StatusBadRequest, responseType, "Invalid request body", nil)
+		return false
+	}
+	return true
+}
+
+// banDuration parses an optional Go duration; suspensions need one.
+func banDuration(value, kind string) (time.Duration, bool) {
+	if value == "" {
+		return 0, kind != model.BanKindSuspension
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil && d > 0
+}
+
+func restrictedWord(kind string) string {
+	if kind == model.BanKindSuspension {
+		return "suspended"
+	}
+	return "banned"
+}
+
+func banLength(ban model.Ban) string {
+	if ban.Until.IsZero() {
+		return "permanent"
+	}
+	return "until " + ban.Until.Format(time.RFC3339)
+}
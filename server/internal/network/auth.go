@@ -4,13 +4,13 @@ package network
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"royaka/internal/game"
 	"royaka/internal/model"
 	"royaka/internal/utils"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func handleRegister(conn *websocket.Conn, data json.RawMessage) {
@@ -18,7 +18,7 @@ func handleRegister(conn *websocket.Conn, data json.RawMessage) {
 
 	if err := json.Unmarshal(data, &req); err != nil {
 		log.Printf("[WARN][AUTH] Invalid register data: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "register_response",
 			Success: false,
 			Message: "Invalid register data",
@@ -26,42 +26,34 @@ func handleRegister(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Printf("[ERROR][AUTH] Password hashing failed for %s: %v", req.Username, err)
-		conn.WriteJSON(utils.Response{
+	if err := RegisterUser(remoteIP(conn), req.Username, req.Password); err != nil {
+		message := "Error hashing password"
+		if errors.Is(err, ErrTooManySignups) {
+			message = "Too many registrations, try again later"
+		} else if !errors.Is(err, ErrHashPassword) {
+			message = "Registration failed: " + errors.Unwrap(err).Error()
+		}
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "register_response",
 			Success: false,
-			Message: "Error hashing password",
+			Message: message,
 		})
 		return
 	}
 
-	err = model.AddUser(*model.NewUser(req.Username, string(hashedPassword)))
-	if err != nil {
-		log.Printf("[WARN][AUTH] Registration failed for %s: %v", req.Username, err)
-		conn.WriteJSON(utils.Response{
-			Type:    "register_response",
-			Success: false,
-			Message: "Registration failed: " + err.Error(),
-		})
-		return
-	}
-
-	log.Printf("[INFO][AUTH] User %s registered successfully", req.Username)
-	conn.WriteJSON(utils.Response{
+	utils.WriteMessage(conn, utils.Response{
 		Type:    "register_response",
 		Success: true,
 		Message: "Registered successfully",
 	})
 }
 
-func handleLogin(conn *websocket.Conn, data json.RawMessage) {
+func (s *Server) handleLogin(conn *websocket.Conn, data json.RawMessage) {
 	var req utils.LoginRequest
 
 	if err := json.Unmarshal(data, &req); err != nil {
 		log.Printf("[WARN][AUTH] Invalid login data: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "login_response",
 			Success: false,
 			Message: "Invalid login data",
@@ -69,54 +61,24 @@ func handleLogin(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	u, ok := model.FindUserByUsername(req.Username)
-	if !ok {
-		log.Printf("[WARN][AUTH] Login failed, user %s not found", req.Username)
-		conn.WriteJSON(utils.Response{
-			Type:    "login_response",
-			Success: false,
-			Message: "Invalid credentials",
-		})
-		return
-	}
-
-	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)) != nil {
-		log.Printf("[WARN][AUTH] Login failed, incorrect password for %s", req.Username)
-		conn.WriteJSON(utils.Response{
-			Type:    "login_response",
-			Success: false,
-			Message: "Invalid credentials",
-		})
-		return
-	}
-
-	sessionID := uuid.New().String()[:8]
-	session := Session{SessionID: sessionID, Username: req.Username, Authenticated: true}
-	log.Printf("[INFO][AUTH] User %s authenticated, session ID: %s", req.Username, sessionID)
-	sessions, err := ReadSessions()
+	sessionID, err := s.sessions.LoginUser(remoteIP(conn), req.Username, req.Password)
 	if err != nil {
-		log.Printf("[ERROR][AUTH] Reading sessions failed: %v", err)
-		conn.WriteJSON(utils.Response{
-			Type:    "login_response",
-			Success: false,
-			Message: "Error reading sessions",
-		})
-		return
-	}
-
-	sessions = append(sessions, session)
-	if err := WriteSession(sessions); err != nil {
-		log.Printf("[ERROR][AUTH] Writing sessions failed: %v", err)
-		conn.WriteJSON(utils.Response{
+		var data interface{}
+		var throttled *ThrottledError
+		if errors.As(err, &throttled) {
+			data = map[string]int64{"retry_after_ms": throttled.RetryAfter.Milliseconds()}
+		}
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "login_response",
 			Success: false,
-			Message: "Error saving session",
+			Message: loginErrorMessage(err, req.Username),
+			Data:    data,
 		})
 		return
 	}
 
-	log.Printf("[INFO][AUTH] Session stored for user %s", req.Username)
-	conn.WriteJSON(utils.Response{
+	game.TrackClient(req.Username, conn)
+	utils.WriteMessage(conn, utils.Response{
 		Type:    "login_response",
 		Success: true,
 		Message: "Login successful",
@@ -124,12 +86,12 @@ func handleLogin(conn *websocket.Conn, data json.RawMessage) {
 	})
 }
 
-func handleGetUser(conn *websocket.Conn, data json.RawMessage) {
+func (s *Server) handleGetUser(conn *websocket.Conn, data json.RawMessage) {
 	var req utils.UserRequest
 
 	if err := json.Unmarshal(data, &req); err != nil {
 		log.Printf("[WARN][AUTH] Invalid session ID in get_user: %v", err)
-		conn.WriteJSON(utils.Response{
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "user_response",
 			Success: false,
 			Message: "Invalid session ID",
@@ -137,30 +99,25 @@ func handleGetUser(conn *websocket.Conn, data json.RawMessage) {
 		return
 	}
 
-	session, err := FindSessionByID(req.SessionID)
+	user, err := s.sessions.UserBySession(req.SessionID)
 	if err != nil {
-		log.Printf("[WARN][AUTH] Session %s not found", req.SessionID)
-		conn.WriteJSON(utils.Response{
+		message := "User not found"
+		if errors.Is(err, ErrSessionNotFound) {
+			message = "Session not found"
+		} else if errors.Is(err, model.ErrBanned) {
+			message = "Account is banned"
+		}
+		utils.WriteMessage(conn, utils.Response{
 			Type:    "user_response",
 			Success: false,
-			Message: "Session not found",
-		})
-		return
-	}
-
-	user, ok := model.FindUserByUsername(session.Username)
-	if !ok {
-		log.Printf("[WARN][AUTH] User %s from session not found", session.Username)
-		conn.WriteJSON(utils.Response{
-			Type:    "user_response",
-			Success: false,
-			Message: "User not found",
+			Message: message,
 		})
 		return
 	}
 
-	log.Printf("[INFO][AUTH] Returning user data for %s (session %s)", user.Username, req.SessionID)
-	conn.WriteJSON(utils.Response{
+	log.Printf("[INFO][AUTH] Returning user data for %s", user.Username)
+	game.TrackClient(user.Username, conn)
+	utils.WriteMessage(conn, utils.Response{
 		Type:    "user_response",
 		Success: true,
 		Data: map[string]interface{}{
@@ -169,3 +126,49 @@ func handleGetUser(conn *websocket.Conn, data json.RawMessage) {
 		},
 	})
 }
+
+// loginErrorMessage maps LoginUser errors to the messages shown to clients.
+func loginErrorMessage(err error, username string) string {
+	var throttled *ThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		return throttled.RetryMessage()
+	case errors.Is(err, ErrReadSessions):
+		return "Error reading sessions"
+	case errors.Is(err, ErrSaveSession):
+		return "Error saving session"
+	case errors.Is(err, model.ErrBanned):
+		user, _ := model.FindUserByUsername(username)
+		return game.RestrictionMessage(user.Ban)
+	default:
+		return "Invalid credentials"
+	}
+}
+
+// handleReattach lets a player with a valid session rejoin a match that was
+// restored after a server restart.
+func (s *Server) handleReattach(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.UserRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.SessionID == "" {
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "reattach_response",
+			Success: false,
+			Message: "Invalid session ID",
+		})
+		return
+	}
+
+	user, err := s.sessions.UserBySession(req.SessionID)
+	if err != nil {
+		log.Printf("[WARN][AUTH] Reattach with unknown session: %v", err)
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "reattach_response",
+			Success: false,
+			Message: "Session not found",
+		})
+		return
+	}
+
+	game.TrackClient(user.Username, conn)
+	game.ReattachPlayer(conn, user.Username)
+}
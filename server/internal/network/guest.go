@@ -0,0 +1,194 @@
+// internal/network/guest.go
+
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"royaka/internal/game"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// guestCleanupInterval is how often idle guests are looked for.
+const guestCleanupInterval = time.Hour
+
+var guestCleanupOnce sync.Once
+
+// GuestLogin creates a guest user and a session for it. Guests count
+// against the same per-IP limit as registrations.
+func (s *Server) GuestLogin(ip string) (model.User, string, error) {
+	if _, ok := registrations.allow(ip); !ok {
+		log.Printf("[WARN][AUTH] Guest login throttled for %s", ip)
+		return model.User{}, "", ErrTooManySignups
+	}
+
+	guest, err := model.NewGuest()
+	if err != nil {
+		log.Printf("[ERROR][AUTH] Creating guest failed: %v", err)
+		return model.User{}, "", err
+	}
+	sessionID, err := s.sessions.RenewSession(guest.Username)
+	if err != nil {
+		log.Printf("[ERROR][AUTH] Storing session for %s failed: %v", guest.Username, err)
+		return model.User{}, "", err
+	}
+
+	log.Printf("[INFO][AUTH] Guest %s created", guest.Username)
+	return guest, sessionID, nil
+}
+
+// ClaimAccount gives a guest a username and password under the
+// registration rules. Their sessions follow the new name.
+func (s *Server) ClaimAccount(guestName, username, password string) (model.User, error) {
+	if err := ValidateRegistration(username, password); err != nil {
+		return model.User{}, fmt.Errorf("registration failed: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, ErrHashPassword
+	}
+
+	claimed, err := model.ClaimGuest(guestName, username, string(hashedPassword))
+	if err != nil && claimed.Username == "" {
+		return model.User{}, err
+	}
+	if err != nil {
+		// The account is claimed; history or reports may still show the guest name
+		log.Printf("[ERROR][AUTH] Renaming guest %s in history failed: %v", guestName, err)
+	}
+	if err := s.sessions.RenameSessions(guestName, claimed.Username); err != nil {
+		log.Printf("[ERROR][AUTH] Renaming sessions of %s failed: %v", guestName, err)
+	}
+
+	log.Printf("[INFO][AUTH] Guest %s claimed the account %s", guestName, claimed.Username)
+	return claimed, nil
+}
+
+// StartGuestCleanup periodically deletes guests that have not logged in
+// for ttl, like deleted accounts.
+func (s *Server) StartGuestCleanup(ttl time.Duration) {
+	guestCleanupOnce.Do(func() {
+		go func() {
+			s.cleanupGuests(ttl)
+			ticker := time.NewTicker(guestCleanupInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				s.cleanupGuests(ttl)
+			}
+		}()
+	})
+}
+
+func (s *Server) cleanupGuests(ttl time.Duration) {
+	idle, err := model.IdleGuests(time.Now().Add(-ttl))
+	if err != nil {
+		log.Printf("[ERROR][AUTH] Looking for idle guests failed: %v", err)
+		return
+	}
+
+	deleted := 0
+	for _, username := range idle {
+		if game.Presence(username) != game.PresenceOffline {
+			continue
+		}
+		if err := model.DeleteUser(username); err != nil {
+			log.Printf("[ERROR][AUTH] Deleting guest %s failed: %v", username, err)
+			if _, exists := model.FindUserByUsername(username); exists {
+				continue
+			}
+		}
+		if err := s.sessions.DropSessions(username); err != nil {
+			log.Printf("[ERROR][AUTH] Dropping sessions of %s failed: %v", username, err)
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		log.Printf("[INFO][AUTH] Deleted %d guests idle for %s", deleted, ttl)
+	}
+}
+
+// ==== HANDLERS ====
+
+func (s *Server) handleGuestLogin(conn *websocket.Conn, data json.RawMessage) {
+	if game.ClientUsername(conn) != "" {
+		writeAccountError(conn, "guest_login_response", "Already logged in")
+		return
+	}
+
+	guest, sessionID, err := s.GuestLogin(remoteIP(conn))
+	if err != nil {
+		message := "Failed to create guest account"
+		if errors.Is(err, ErrTooManySignups) {
+			message = "Too many new accounts, try again later"
+		}
+		writeAccountError(conn, "guest_login_response", message)
+		return
+	}
+
+	game.TrackClient(guest.Username, conn)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "guest_login_response",
+		Success: true,
+		Message: "Playing as " + guest.Username,
+		Data: map[string]interface{}{
+			"session_id": sessionID,
+			"username":   guest.Username,
+		},
+	})
+}
+
+func (s *Server) handleClaimAccount(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ClaimAccountRequest
+	user, ok := accountRequest(conn, data, &req, "claim_account_response")
+	if !ok {
+		return
+	}
+	guestName := user.Username
+
+	if !user.Guest {
+		writeAccountError(conn, "claim_account_response", "Account is already registered")
+		return
+	}
+	// Only whoever holds the guest's session may give it a password
+	if session, err := s.sessions.FindSessionByID(req.SessionID); err != nil || session.Username != guestName {
+		log.Printf("[WARN][AUTH] Claim of %s without its session", guestName)
+		writeAccountError(conn, "claim_account_response", "Session not found")
+		return
+	}
+	if game.Presence(guestName) != game.PresenceOnline {
+		writeAccountError(conn, "claim_account_response", "Leave matchmaking and your match first")
+		return
+	}
+
+	claimed, err := s.ClaimAccount(guestName, req.Username, req.Password)
+	if err != nil {
+		message := "Failed to claim account"
+		switch {
+		case invalidRegistration(err):
+			message = "Registration failed: " + errors.Unwrap(err).Error()
+		case errors.Is(err, model.ErrUserExists):
+			message = "Username is taken"
+		default:
+			log.Printf("[ERROR][AUTH] Claiming %s failed: %v", guestName, err)
+		}
+		writeAccountError(conn, "claim_account_response", message)
+		return
+	}
+	game.RenameClient(guestName, claimed.Username, claimed.Friends)
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "claim_account_response",
+		Success: true,
+		Message: "Account registered",
+		Data:    map[string]string{"username": claimed.Username},
+	})
+}
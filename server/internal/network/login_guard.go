@@ -0,0 +1,159 @@
+// internal/network/login_guard.go
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// loginFreeFailures failed logins are allowed before each further
+	// attempt has to wait, doubling from loginBackoff up to loginMaxBackoff.
+	loginFreeFailures = 3
+	loginBackoff      = time.Second
+	loginMaxBackoff   = 30 * time.Second
+
+	// After this many failures a username, or an IP trying many names, is
+	// locked out for loginLockout.
+	userLockoutFailures = 10
+	ipLockoutFailures   = 30
+	loginLockout        = 15 * time.Minute
+
+	// loginForget drops the failures of a username or IP that stopped
+	// failing for this long.
+	loginForget = 15 * time.Minute
+)
+
+var ErrTooManyAttempts = errors.New("too many login attempts")
+
+// ThrottledError is returned by LoginUser while the username or client IP
+// has to wait before trying again.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%v, retry in %s", ErrTooManyAttempts, e.RetryAfter)
+}
+
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// RetryMessage is the text shown to the client.
+func (e *ThrottledError) RetryMessage() string {
+	return fmt.Sprintf("Too many login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+type loginFailures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// wait returns how long until the next attempt is allowed.
+func (f *loginFailures) wait(now time.Time) time.Duration {
+	if now.Before(f.lockedUntil) {
+		return f.lockedUntil.Sub(now)
+	}
+	if f.count < loginFreeFailures {
+		return 0
+	}
+	backoff := min(loginBackoff<<(f.count-loginFreeFailures), loginMaxBackoff)
+	return max(0, f.last.Add(backoff).Sub(now))
+}
+
+// loginGuard counts failed logins per username and per client IP.
+type loginGuard struct {
+	mu        sync.Mutex
+	byUser    map[string]*loginFailures
+	byIP      map[string]*loginFailures
+	lastPrune time.Time
+}
+
+var logins = &loginGuard{
+	byUser: make(map[string]*loginFailures),
+	byIP:   make(map[string]*loginFailures),
+}
+
+// check returns a ThrottledError if the attempt has to wait.
+func (g *loginGuard) check(ip, username string) error {
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var wait time.Duration
+	if f := g.byUser[strings.ToLower(username)]; f != nil {
+		wait = f.wait(now)
+	}
+	if f := g.byIP[ip]; f != nil && ip != "" {
+		wait = max(wait, f.wait(now))
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (g *loginGuard) failed(ip, username string) {
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prune(now)
+
+	if g.fail(g.byUser, strings.ToLower(username), userLockoutFailures, now) {
+		log.Printf("[WARN][AUTH] Locking logins for %s for %s after repeated failures", username, loginLockout)
+	}
+	if ip != "" && g.fail(g.byIP, ip, ipLockoutFailures, now) {
+		log.Printf("[WARN][AUTH] Locking logins from %s for %s after repeated failures", ip, loginLockout)
+	}
+}
+
+// fail counts one failure and reports whether it started a lockout.
+func (g *loginGuard) fail(table map[string]*loginFailures, key string, lockoutAfter int, now time.Time) bool {
+	f := table[key]
+	if f == nil || now.Sub(f.last) > loginForget {
+		f = &loginFailures{}
+		table[key] = f
+	}
+	f.count++
+	f.last = now
+
+	if f.count < lockoutAfter {
+		return false
+	}
+	f.count = 0
+	f.lockedUntil = now.Add(loginLockout)
+	return true
+}
+
+// succeeded clears the username's failures. The IP keeps its count so one
+// valid account cannot be used to reset guessing at others.
+func (g *loginGuard) succeeded(username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.byUser, strings.ToLower(username))
+}
+
+// prune drops forgotten entries, at most once a minute.
+func (g *loginGuard) prune(now time.Time) {
+	if now.Sub(g.lastPrune) < time.Minute {
+		return
+	}
+	g.lastPrune = now
+
+	for _, table := range []map[string]*loginFailures{g.byUser, g.byIP} {
+		for key, f := range table {
+			if now.Sub(f.last) > loginForget && now.After(f.lockedUntil) {
+				delete(table, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// internal/network/profile.go
+
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"royaka/internal/game"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const renameDateFormat = "2006-01-02"
+
+func (s *Server) handleChangePassword(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChangePasswordRequest
+	user, ok := accountRequest(conn, data, &req, "change_password_response")
+	if !ok {
+		return
+	}
+	if user.Guest {
+		writeAccountError(conn, "change_password_response", "Claim your account first")
+		return
+	}
+
+	if err := CheckPassword(remoteIP(conn), user, req.OldPassword); err != nil {
+		writeAccountError(conn, "change_password_response", passwordErrorMessage(err))
+		return
+	}
+	if req.NewPassword == req.OldPassword {
+		writeAccountError(conn, "change_password_response", "New password must be different")
+		return
+	}
+	if err := ValidatePassword(user.Username, req.NewPassword); err != nil {
+		writeAccountError(conn, "change_password_response", "Invalid password: "+err.Error())
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		writeAccountError(conn, "change_password_response", "Error hashing password")
+		return
+	}
+	if _, err := model.UpdateUser(user.Username, func(u *model.User) error {
+		u.Password = string(hashed)
+		return nil
+	}); err != nil {
+		log.Printf("[ERROR][ACCOUNT] Saving password for %s failed: %v", user.Username, err)
+		writeAccountError(conn, "change_password_response", "Failed to change password")
+		return
+	}
+
+	// Sessions from before the change, possibly leaked, stop working
+	sessionID, err := s.sessions.RenewSession(user.Username)
+	if err != nil {
+		log.Printf("[ERROR][ACCOUNT] Renewing session for %s failed: %v", user.Username, err)
+	}
+
+	log.Printf("[INFO][ACCOUNT] %s changed their password", user.Username)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "change_password_response",
+		Success: true,
+		Message: "Password changed",
+		Data:    map[string]string{"session_id": sessionID},
+	})
+}
+
+func handleChangeAvatar(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChangeAvatarRequest
+	user, ok := accountRequest(conn, data, &req, "change_avatar_response")
+	if !ok {
+		return
+	}
+
+	updated, err := model.SetAvatar(user.Username, req.Avatar)
+	if err != nil {
+		message := "Failed to change avatar"
+		if errors.Is(err, model.ErrAvatarLocked) {
+			message = "Avatar is not available"
+		}
+		utils.WriteMessage(conn, utils.Response{
+			Type:    "change_avatar_response",
+			Success: false,
+			Message: message,
+			Data:    map[string]interface{}{"avatars": user.AvatarOptions()},
+		})
+		return
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "change_avatar_response",
+		Success: true,
+		Message: "Avatar changed",
+		Data: map[string]interface{}{
+			"avatar":  updated.Avatar,
+			"avatars": updated.AvatarOptions(),
+		},
+	})
+}
+
+func (s *Server) handleChangeUsername(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.ChangeUsernameRequest
+	user, ok := accountRequest(conn, data, &req, "change_username_response")
+	if !ok {
+		return
+	}
+	if user.Guest {
+		writeAccountError(conn, "change_username_response", "Claim your account first")
+		return
+	}
+	oldName := user.Username
+
+	if err := CheckPassword(remoteIP(conn), user, req.Password); err != nil {
+		writeAccountError(conn, "change_username_response", passwordErrorMessage(err))
+		return
+	}
+	if req.NewUsername == oldName {
+		writeAccountError(conn, "change_username_response", "That is already your username")
+		return
+	}
+	if err := ValidateUsername(req.NewUsername); err != nil {
+		writeAccountError(conn, "change_username_response", "Invalid username: "+err.Error())
+		return
+	}
+	if game.Presence(oldName) != game.PresenceOnline {
+		writeAccountError(conn, "change_username_response", "Leave matchmaking and your match first")
+		return
+	}
+
+	renamed, err := model.RenameUser(oldName, req.NewUsername)
+	switch {
+	case errors.Is(err, model.ErrUserExists):
+		writeAccountError(conn, "change_username_response", "Username is taken")
+		return
+	case errors.Is(err, model.ErrRenameCooldown):
+		writeAccountError(conn, "change_username_response",
+			"You can change your username again on "+user.NextRename().UTC().Format(renameDateFormat))
+		return
+	case err != nil && renamed.Username == "":
+		log.Printf("[ERROR][ACCOUNT] Renaming %s failed: %v", oldName, err)
+		writeAccountError(conn, "change_username_response", "Failed to change username")
+		return
+	case err != nil:
+		// The account is renamed; history or reports may still show the old name
+		log.Printf("[ERROR][ACCOUNT] Renaming %s in history failed: %v", oldName, err)
+	}
+
+	if err := s.sessions.RenameSessions(oldName, renamed.Username); err != nil {
+		log.Printf("[ERROR][ACCOUNT] Renaming sessions of %s failed: %v", oldName, err)
+	}
+	game.RenameClient(oldName, renamed.Username, renamed.Friends)
+
+	log.Printf("[INFO][ACCOUNT] %s is now %s", oldName, renamed.Username)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "change_username_response",
+		Success: true,
+		Message: "Username changed",
+		Data: map[string]interface{}{
+			"username":    renamed.Username,
+			"next_change": renamed.NextRename(),
+		},
+	})
+}
+
+func (s *Server) handleDeleteAccount(conn *websocket.Conn, data json.RawMessage) {
+	var req utils.DeleteAccountRequest
+	user, ok := accountRequest(conn, data, &req, "delete_account_response")
+	if !ok {
+		return
+	}
+
+	if req.Confirm != user.Username {
+		writeAccountError(conn, "delete_account_response", "Type your username to confirm")
+		return
+	}
+	// Guests have no password to confirm with
+	if !user.Guest {
+		if err := CheckPassword(remoteIP(conn), user, req.Password); err != nil {
+			writeAccountError(conn, "delete_account_response", passwordErrorMessage(err))
+			return
+		}
+	}
+	if game.Presence(user.Username) != game.PresenceOnline {
+		writeAccountError(conn, "delete_account_response", "Leave matchmaking and your match first")
+		return
+	}
+
+	if err := model.DeleteUser(user.Username); err != nil {
+		log.Printf("[ERROR][ACCOUNT] Deleting %s failed: %v", user.Username, err)
+		// Past the user itself, only anonymizing their history failed
+		if _, exists := model.FindUserByUsername(user.Username); exists {
+			writeAccountError(conn, "delete_account_response", "Failed to delete account")
+			return
+		}
+	}
+	if err := s.sessions.DropSessions(user.Username); err != nil {
+		log.Printf("[ERROR][ACCOUNT] Dropping sessions of %s failed: %v", user.Username, err)
+	}
+	game.UntrackClient(conn)
+	game.AccountDeleted(user.Username, user.Friends)
+
+	log.Printf("[INFO][ACCOUNT] %s deleted their account", user.Username)
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "delete_account_response",
+		Success: true,
+		Message: "Account deleted",
+	})
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "account deleted")
+	conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+}
+
+// accountRequest decodes an account change sent by a logged in user and
+// loads that user, answering with an error otherwise.
+func accountRequest(conn *websocket.Conn, data json.RawMessage, req any, responseType string) (model.User, bool) {
+	username := game.ClientUsername(conn)
+	if username == "" {
+		writeAccountError(conn, responseType, "Login required")
+		return model.User{}, false
+	}
+	if err := json.Unmarshal(data, req); err != nil {
+		writeAccountError(conn, responseType, "Invalid request")
+		return model.User{}, false
+	}
+
+	user, ok := model.FindUserByUsername(username)
+	if !ok {
+		writeAccountError(conn, responseType, "User not found")
+		return model.User{}, false
+	}
+	return user, true
+}
+
+func passwordErrorMessage(err error) string {
+	var throttled *ThrottledError
+	if errors.As(err, &throttled) {
+		return throttled.RetryMessage()
+	}
+	return "Password is incorrect"
+}
+
+func writeAccountError(conn *websocket.Conn, responseType, message string) {
+	utils.WriteMessage(conn, utils.Response{
+		Type:    responseType,
+		Success: false,
+		Message: message,
+	})
+}
@@ -0,0 +1,262 @@
+// internal/network/ratelimit.go
+
+package network
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"royaka/internal/metrics"
+	"royaka/internal/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// limit allows Burst messages at once, refilled at Rate per second.
+type limit struct {
+	Rate  float64
+	Burst float64
+}
+
+var (
+	// messageLimits apply per connection and message type; other types
+	// share defaultLimit.
+	messageLimits = map[string]limit{
+		"register":            {Rate: 0.2, Burst: 3},
+		"login":               {Rate: 0.5, Burst: 5},
+		"reattach":            {Rate: 0.5, Burst: 5},
+		"guest_login":         {Rate: 0.2, Burst: 3},
+		"claim_account":       {Rate: 0.2, Burst: 3},
+		"change_password":     {Rate: 0.1, Burst: 3},
+		"change_avatar":       {Rate: 0.5, Burst: 5},
+		"change_username":     {Rate: 0.1, Burst: 3},
+		"delete_account":      {Rate: 0.1, Burst: 3},
+		"find_match":          {Rate: 0.5, Burst: 3},
+		"challenge_friend":    {Rate: 0.2, Burst: 3},
+		"send_friend_request": {Rate: 0.2, Burst: 5},
+		"report_player":       {Rate: 0.1, Burst: 3},
+		"select_troop":        {Rate: 4, Burst: 8},
+		"attack":              {Rate: 4, Burst: 8},
+		"heal":                {Rate: 4, Burst: 8},
+		"skip_turn":           {Rate: 2, Burst: 4},
+		"boost":               {Rate: 2, Burst: 4},
+		"fortify":             {Rate: 2, Burst: 4},
+		"swap_card":           {Rate: 2, Burst: 4},
+		"buy_offer":           {Rate: 1, Burst: 5},
+		"open_chest":          {Rate: 1, Burst: 5},
+		"upgrade_card":        {Rate: 1, Burst: 5},
+	}
+	defaultLimit = limit{Rate: 10, Burst: 20}
+
+	// violationLimit is how many rejected messages a connection may send
+	// before it is closed.
+	violationLimit = limit{Rate: 1, Burst: 30}
+
+	// registerLimit throttles account creation per client IP across
+	// connections and the HTTP API.
+	registerLimit = limit{Rate: 1.0 / 60, Burst: 5}
+)
+
+var rateLimited = metrics.NewCounter("royaka_rate_limited_total",
+	"Messages and requests rejected by rate limits, by message type.", "type")
+
+// ==== TOKEN BUCKET ====
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take spends one token if available, otherwise it returns how long until
+// one is.
+func (b *bucket) take(l limit, now time.Time) (time.Duration, bool) {
+	if b.last.IsZero() {
+		b.tokens = l.Burst
+	} else {
+		b.tokens = min(l.Burst, b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / l.Rate * float64(time.Second)), false
+}
+
+// ==== PER CONNECTION ====
+
+// messageLimiter tracks one connection's buckets. It is only used from the
+// connection's read loop.
+type messageLimiter struct {
+	buckets    map[string]*bucket
+	violations bucket
+}
+
+func newMessageLimiter() *messageLimiter {
+	return &messageLimiter{buckets: make(map[string]*bucket)}
+}
+
+// allow reports whether a message of the given type may be handled now,
+// and if not, when to retry.
+func (l *messageLimiter) allow(messageType string, now time.Time) (time.Duration, bool) {
+	key, lim := messageType, defaultLimit
+	if configured, ok := messageLimits[messageType]; ok {
+		lim = configured
+	} else {
+		key = "*"
+	}
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{}
+		l.buckets[key] = b
+	}
+	return b.take(lim, now)
+}
+
+// violate records a rejected message and reports whether the connection
+// has exceeded its allowance and should be closed.
+func (l *messageLimiter) violate(now time.Time) bool {
+	_, ok := l.violations.take(violationLimit, now)
+	return !ok
+}
+
+// rejectRateLimited tells the client to slow down, closing the connection
+// if it keeps ignoring the limits. It reports whether the connection stays
+// open.
+func rejectRateLimited(conn *websocket.Conn, limiter *messageLimiter, messageType string, retry time.Duration) bool {
+	label := messageType
+	if _, ok := messageLimits[messageType]; !ok {
+		label = "other"
+	}
+	rateLimited.Inc(label)
+
+	if limiter.violate(time.Now()) {
+		log.Printf("[WARN][RATE] Closing %s for ignoring rate limits", remoteIP(conn))
+		closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded")
+		conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+		return false
+	}
+
+	utils.WriteMessage(conn, utils.Response{
+		Type:    "rate_limited",
+		Success: false,
+		Message: "Too many requests, slow down",
+		Data: map[string]interface{}{
+			"type":           messageType,
+			"retry_after_ms": retry.Milliseconds(),
+		},
+	})
+	return true
+}
+
+// ==== PER IP ====
+
+// ipLimiter keeps a bucket per client IP, shared by every connection.
+type ipLimiter struct {
+	limit limit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+var registrations = &ipLimiter{limit: registerLimit, buckets: make(map[string]*bucket)}
+
+func (l *ipLimiter) allow(ip string) (time.Duration, bool) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	b := l.buckets[ip]
+	if b == nil {
+		b = &bucket{}
+		l.buckets[ip] = b
+	}
+	return b.take(l.limit, now)
+}
+
+// prune drops buckets that have refilled completely, at most once a minute.
+func (l *ipLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+
+	full := time.Duration(l.limit.Burst / l.limit.Rate * float64(time.Second))
+	for ip, b := range l.buckets {
+		if now.Sub(b.last) > full {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// ==== CLIENT ADDRESSES ====
+
+var (
+	// WebSocket connections keep the client IP seen at the handshake.
+	connIPs   = make(map[*websocket.Conn]string)
+	connIPsMu sync.RWMutex
+)
+
+// clientIP returns the address of the client behind r. X-Forwarded-For is
+// only believed when the request comes from one of the configured proxies.
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !s.isTrustedProxy(addr) {
+		return host
+	}
+
+	// The last address not added by one of our proxies is the client
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		if !s.isTrustedProxy(hop) {
+			return hop.String()
+		}
+	}
+	return host
+}
+
+func (s *Server) isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range s.cfg.Proxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+func trackConnIP(conn *websocket.Conn, ip string) {
+	connIPsMu.Lock()
+	defer connIPsMu.Unlock()
+	connIPs[conn] = ip
+}
+
+func untrackConnIP(conn *websocket.Conn) {
+	connIPsMu.Lock()
+	defer connIPsMu.Unlock()
+	delete(connIPs, conn)
+}
+
+func remoteIP(conn *websocket.Conn) string {
+	connIPsMu.RLock()
+	defer connIPsMu.RUnlock()
+	return connIPs[conn]
+}
@@ -0,0 +1,284 @@
+// internal/network/rest.go
+
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"royaka/internal/game"
+	"royaka/internal/model"
+	"royaka/internal/utils"
+)
+
+// RegisterAPI mounts the plain HTTP API under /api/ on mux. It shares the
+// account logic of the WebSocket handlers and authenticates with the same
+// session IDs, passed as "Authorization: Bearer <session_id>".
+func (s *Server) RegisterAPI(mux *http.ServeMux) {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /api/register", s.apiRegister)
+	api.HandleFunc("POST /api/login", s.apiLogin)
+	api.HandleFunc("POST /api/logout", s.apiLogout)
+	api.HandleFunc("GET /api/profile", s.apiProfile)
+	api.HandleFunc("GET /api/cards", apiCards)
+	api.HandleFunc("GET /api/leaderboard", s.apiLeaderboard)
+	api.HandleFunc("GET /api/matches", s.apiMatches)
+	api.HandleFunc("GET /api/matches/{id}/replay", s.apiReplay)
+
+	mux.Handle("/api/", withCORS(api))
+}
+
+func (s *Server) apiRegister(w http.ResponseWriter, r *http.Request) {
+	var req utils.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPI(w, http.StatusBadRequest, "register_response", "Invalid register data", nil)
+		return
+	}
+
+	if err := RegisterUser(s.clientIP(r), req.Username, req.Password); err != nil {
+		if errors.Is(err, model.ErrUserExists) {
+			writeAPI(w, http.StatusConflict, "register_response", "Registration failed: "+model.ErrUserExists.Error(), nil)
+			return
+		}
+		if errors.Is(err, ErrTooManySignups) {
+			writeAPI(w, http.StatusTooManyRequests, "register_response", "Too many registrations, try again later", nil)
+			return
+		}
+		if invalidRegistration(err) {
+			writeAPI(w, http.StatusBadRequest, "register_response", "Registration failed: "+errors.Unwrap(err).Error(), nil)
+			return
+		}
+		writeAPI(w, http.StatusInternalServerError, "register_response", "Registration failed", nil)
+		return
+	}
+
+	writeAPI(w, http.StatusCreated, "register_response", "Registered successfully", nil)
+}
+
+func (s *Server) apiLogin(w http.ResponseWriter, r *http.Request) {
+	var req utils.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPI(w, http.StatusBadRequest, "login_response", "Invalid login data", nil)
+		return
+	}
+
+	sessionID, err := s.sessions.LoginUser(s.clientIP(r), req.Username, req.Password)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var throttled *ThrottledError
+		if errors.Is(err, ErrInvalidCredentials) {
+			status = http.StatusUnauthorized
+		} else if errors.Is(err, model.ErrBanned) {
+			status = http.StatusForbidden
+		} else if errors.As(err, &throttled) {
+			status = http.StatusTooManyRequests
+			setRetryAfter(w, throttled.RetryAfter)
+		}
+		writeAPI(w, status, "login_response", loginErrorMessage(err, req.Username), nil)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, "login_response", "Login successful", map[string]string{"session_id": sessionID})
+}
+
+func (s *Server) apiLogout(w http.ResponseWriter, r *http.Request) {
+	if err := s.sessions.LogoutSession(bearerToken(r)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrSessionNotFound) {
+			status = http.StatusUnauthorized
+		}
+		writeAPI(w, status, "logout_response", err.Error(), nil)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, "logout_response", "Logged out", nil)
+}
+
+func (s *Server) apiProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authenticate(w, r, "user_response")
+	if !ok {
+		return
+	}
+
+	user.Password = ""
+	writeAPI(w, http.StatusOK, "user_response", "", map[string]interface{}{
+		"user":   user,
+		"maxExp": model.GetMaxExp(user.Level),
+	})
+}
+
+func apiCards(w http.ResponseWriter, r *http.Request) {
+	troops, err := model.LoadTroop()
+	if err != nil {
+		log.Println("loadTroop error:", err)
+		writeAPI(w, http.StatusInternalServerError, "deck_response", "Failed to load troops", nil)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, "deck_response", "Troop data loaded", troops)
+}
+
+func (s *Server) apiLeaderboard(w http.ResponseWriter, r *http.Request) {
+	offset := queryInt(r, "offset", 0)
+	limit := min(queryInt(r, "limit", 50), 100)
+	scope := r.URL.Query().Get("scope")
+
+	var (
+		entries []model.LeaderboardEntry
+		total   int
+		err     error
+	)
+	switch scope {
+	case "", "global":
+		scope = "global"
+		entries, total, err = model.Leaderboard(offset, limit)
+	case "friends":
+		user, ok := s.authenticate(w, r, "leaderboard_response")
+		if !ok {
+			return
+		}
+		entries, total, err = model.FriendsLeaderboard(user.Username, offset, limit)
+	default:
+		writeAPI(w, http.StatusBadRequest, "leaderboard_response", "Unknown leaderboard scope", nil)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR][API] Loading leaderboard failed: %v", err)
+		writeAPI(w, http.StatusInternalServerError, "leaderboard_response", "Failed to load leaderboard", nil)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, "leaderboard_response", "", map[string]interface{}{
+		"scope":   scope,
+		"entries": entries,
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
+	})
+}
+
+func (s *Server) apiMatches(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authenticate(w, r, "match_history_response")
+	if !ok {
+		return
+	}
+
+	payload, err := game.MatchHistoryPage(user.Username, queryInt(r, "offset", 0), queryInt(r, "limit", 0))
+	if err != nil {
+		log.Printf("[ERROR][API] Loading match history failed: %v", err)
+		writeAPI(w, http.StatusInternalServerError, "match_history_response", "Failed to load match history", nil)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, "match_history_response", "", payload)
+}
+
+// apiReplay streams the stored gzip replay of a match to one of its players.
+func (s *Server) apiReplay(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authenticate(w, r, "replay_response")
+	if !ok {
+		return
+	}
+
+	match, err := model.FindMatch(r.PathValue("id"))
+	if err == nil {
+		if _, played := match.Player(user.Username); !played || !match.HasReplay {
+			err = model.ErrMatchNotFound
+		}
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrMatchNotFound) {
+			status = http.StatusNotFound
+		}
+		writeAPI(w, status, "replay_response", "Replay not found", nil)
+		return
+	}
+
+	serveReplay(w, match)
+}
+
+// serveReplay streams a match's stored gzip replay.
+func serveReplay(w http.ResponseWriter, match model.MatchRecord) {
+	file, err := model.OpenReplay(match.ID)
+	if err != nil {
+		log.Printf("[ERROR][API] Opening replay %s failed: %v", match.ID, err)
+		writeAPI(w, http.StatusNotFound, "replay_response", "Replay not found", nil)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="replay-`+match.ID+`.json"`)
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("[ERROR][API] Sending replay %s failed: %v", match.ID, err)
+	}
+}
+
+// ==== HELPERS ====
+
+// authenticate resolves the bearer session to a user, writing a 401 on failure.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request, responseType string) (model.User, bool) {
+	user, err := s.sessions.UserBySession(bearerToken(r))
+	if err != nil {
+		writeAPI(w, http.StatusUnauthorized, responseType, "Session not found", nil)
+		return model.User{}, false
+	}
+	return user, true
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds, rounded up.
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func queryInt(r *http.Request, key string, fallback int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+func writeAPI(w http.ResponseWriter, status int, responseType, message string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(utils.Response{
+		Type:    responseType,
+		Success: status < http.StatusBadRequest,
+		Message: message,
+		Data:    data,
+	})
+	if err != nil {
+		log.Printf("[ERROR][API] Failed to write response: %v", err)
+	}
+}
+
+// withCORS lets the browser client call the API from another origin.
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,32 +3,161 @@
 package network
 
 import (
-	"encoding/json"
+	"context"
 	"log"
+	"log/slog"
 	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"royaka/internal/config"
 	"royaka/internal/game"
+	"royaka/internal/metrics"
 	"royaka/internal/model"
 	"royaka/internal/utils"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
+// Server serves the WebSocket endpoint, the HTTP API and static assets
+// with the settings from the server config. Matches are started by the
+// game engine built from the same config.
+type Server struct {
+	cfg      config.Config
+	engine   *game.Engine
+	sessions *SessionStore
+	upgrader websocket.Upgrader
+
+	conns   map[*websocket.Conn]struct{}
+	connsMu sync.Mutex
+}
+
+var handlerDuration = metrics.NewHistogram("royaka_handler_duration_seconds",
+	"Time spent handling a WebSocket message, by message type.", metrics.DefBuckets, "type")
+
+// NewServer keeps cfg on the Server, with its sessions stored in the
+// config's data directory.
+func NewServer(cfg config.Config, engine *game.Engine) *Server {
+	s := &Server{
+		cfg:      cfg,
+		engine:   engine,
+		sessions: NewSessionStore(cfg.DataDir),
+		conns:    make(map[*websocket.Conn]struct{}),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return cfg.AllowsOrigin(r.Header.Get("Origin"))
+			},
+			// JSON stays the default; clients may ask for MessagePack via subprotocol
+			Subprotocols:      utils.Subprotocols,
+			EnableCompression: true,
+		},
+	}
+	metrics.NewGauge("royaka_connections_active", "Open WebSocket connections.", func() float64 {
+		s.connsMu.Lock()
+		defer s.connsMu.Unlock()
+		return float64(len(s.conns))
+	})
+	return s
+}
+
+// Handler routes /ws, /metrics, the health probes, /api/, /admin/ and
+// /assets/.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	fs := hideDataDir(http.FileServer(http.Dir(staticRoot)), s.cfg.DataDir)
+	mux.Handle("/assets/", http.StripPrefix("/assets/", fs))
+
+	mux.HandleFunc("/ws", s.HandleWebSocket)
+	mux.Handle("GET /metrics", metrics.Handler())
+	RegisterHealth(mux)
+	s.RegisterAPI(mux)
+	RegisterAdmin(mux, s.cfg.AdminToken)
+	return mux
+}
+
+// staticRoot is served at /assets/.
+const staticRoot = "./assets"
+
+// hideDataDir answers 404 for anything in the data directory when it lies
+// inside the static root: it holds users, sessions, reports, history and
+// replays.
+func hideDataDir(next http.Handler, dataDir string) http.Handler {
+	root, err1 := filepath.Abs(staticRoot)
+	dir, err2 := filepath.Abs(dataDir)
+	if err1 != nil || err2 != nil {
+		// Without both paths nothing can be served safely
+		return http.NotFoundHandler()
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return next
+	}
+
+	// Compared without case for case-insensitive filesystems
+	prefix := strings.ToLower(filepath.ToSlash(rel))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ToLower(strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/"))
+		if prefix == "." || name == prefix || strings.HasPrefix(name, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
-func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+// CloseConnections sends every WebSocket a "going away" close frame and
+// waits up to timeout for the clients to hang up before closing the rest.
+func (s *Server) CloseConnections(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+
+	s.connsMu.Lock()
+	for conn := range s.conns {
+		if err := conn.WriteControl(websocket.CloseMessage, closeMsg, deadline); err != nil {
+			conn.Close()
+		}
+	}
+	s.connsMu.Unlock()
+
+	for time.Now().Before(deadline) {
+		s.connsMu.Lock()
+		open := len(s.conns)
+		s.connsMu.Unlock()
+		if open == 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	s.connsMu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.connsMu.Unlock()
+}
+
+func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if game.Draining() {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("[ERROR][WS] Upgrade failed: %v", err)
 		http.Error(w, "WebSocket upgrade failed", http.StatusInternalServerError)
 		return
 	}
 
+	s.connsMu.Lock()
+	s.conns[conn] = struct{}{}
+	s.connsMu.Unlock()
+	trackConnIP(conn, s.clientIP(r))
+
 	// Recover panic inside the goroutine safely
 	defer func() {
 		if r := recover(); r != nil {
@@ -42,10 +171,17 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 			game.CleanupUser(player.User.Username)
 		}
 		game.HandleDisconnect(conn)
+		game.RemoveSpectator(conn)
+		game.UntrackClient(conn)
+		untrackConnIP(conn)
+
+		s.connsMu.Lock()
+		delete(s.conns, conn)
+		s.connsMu.Unlock()
 		log.Println("[WS] Connection closed")
 	}()
 
-	log.Println("[WS] WebSocket connection established")
+	log.Printf("[WS] WebSocket connection established (encoding: %s)", encodingName(conn))
 
 	go func() {
 		ticker := time.NewTicker(100 * time.Millisecond)
@@ -60,47 +196,122 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	limiter := newMessageLimiter()
 	for {
 
-		if !readAndProcessMessage(conn) {
+		if !s.readAndProcessMessage(conn, limiter) {
 			break
 		}
 	}
 }
 
-func readAndProcessMessage(conn *websocket.Conn) bool {
-	_, msg, err := conn.ReadMessage()
+func (s *Server) readAndProcessMessage(conn *websocket.Conn, limiter *messageLimiter) bool {
+	frameType, msg, err := conn.ReadMessage()
 	if err != nil {
 		logWebSocketError(err)
 		return false
 	}
 
-	log.Printf("[INFO][WS] Raw message: %s", msg)
-
-	var pdu utils.Message
-	if err := json.Unmarshal(msg, &pdu); err != nil {
-		log.Printf("[WARN][WS] Invalid JSON: %v", err)
+	pdu, err := utils.DecodeMessage(frameType, msg)
+	if err != nil {
+		slog.Warn("Invalid message", "area", "ws", "bytes", len(msg), "error", err)
 		sendError(conn, "Invalid message format")
 		return true
 	}
 
-	log.Printf("[INFO][WS] Message type: %s", pdu.Type)
-	processMessage(conn, pdu)
+	if retry, ok := limiter.allow(pdu.Type, time.Now()); !ok {
+		return rejectRateLimited(conn, limiter, pdu.Type, retry)
+	}
+
+	// Messages are only logged at debug level, with credentials redacted
+	logger := messageLogger(conn, pdu.Type)
+	if logger != nil {
+		logger.Debug("Message received", "data", utils.RedactJSON(pdu.Data))
+	}
+
+	started := time.Now()
+	messageType := pdu.Type
+	if !s.processMessage(conn, pdu) {
+		// Keep made-up types from growing the label set
+		messageType = "unknown"
+	}
+	elapsed := time.Since(started)
+	handlerDuration.Observe(elapsed.Seconds(), messageType)
+	if logger != nil {
+		logger.Debug("Message handled", "duration", elapsed)
+	}
 	return true
 }
 
-func processMessage(conn *websocket.Conn, pdu utils.Message) {
+// messageLogger returns a logger carrying the message type and sender, or
+// nil when debug logging is off.
+func messageLogger(conn *websocket.Conn, messageType string) *slog.Logger {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return nil
+	}
+	logger := slog.With("area", "ws", "type", messageType)
+	if username := game.ClientUsername(conn); username != "" {
+		logger = logger.With("username", username)
+	}
+	return logger
+}
+
+// processMessage dispatches a message to its handler and reports whether
+// the type was known.
+func (s *Server) processMessage(conn *websocket.Conn, pdu utils.Message) bool {
 	switch pdu.Type {
 	case "register":
 		handleRegister(conn, pdu.Data)
 	case "login":
-		handleLogin(conn, pdu.Data)
+		s.handleLogin(conn, pdu.Data)
+	case "guest_login":
+		s.handleGuestLogin(conn, pdu.Data)
+	case "claim_account":
+		s.handleClaimAccount(conn, pdu.Data)
 	case "get_user":
-		handleGetUser(conn, pdu.Data)
+		s.handleGetUser(conn, pdu.Data)
+	case "reattach":
+		s.handleReattach(conn, pdu.Data)
+	case "change_password":
+		s.handleChangePassword(conn, pdu.Data)
+	case "change_avatar":
+		handleChangeAvatar(conn, pdu.Data)
+	case "change_username":
+		s.handleChangeUsername(conn, pdu.Data)
+	case "delete_account":
+		s.handleDeleteAccount(conn, pdu.Data)
 	case "get_desk":
 		game.HandleGetDesk(conn, pdu.Data)
+	case "get_collection":
+		game.HandleGetCollection(conn, pdu.Data)
+	case "upgrade_card":
+		game.HandleUpgradeCard(conn, pdu.Data)
+	case "get_shop":
+		game.HandleGetShop(conn, pdu.Data)
+	case "buy_offer":
+		game.HandleBuyOffer(conn, pdu.Data)
+	case "get_chests":
+		game.HandleGetChests(conn, pdu.Data)
+	case "open_chest":
+		game.HandleOpenChest(conn, pdu.Data)
+	case "get_leaderboard":
+		game.HandleGetLeaderboard(conn, pdu.Data)
+	case "get_season":
+		game.HandleGetSeason(conn, pdu.Data)
+	case "get_match_history":
+		game.HandleGetMatchHistory(conn, pdu.Data)
+	case "get_quests":
+		game.HandleGetQuests(conn, pdu.Data)
+	case "get_decks":
+		game.HandleGetDecks(conn, pdu.Data)
+	case "save_deck":
+		game.HandleSaveDeck(conn, pdu.Data)
+	case "delete_deck":
+		game.HandleDeleteDeck(conn, pdu.Data)
+	case "set_active_deck":
+		game.HandleSetActiveDeck(conn, pdu.Data)
 	case "find_match":
-		game.HandleFindMatch(conn, pdu.Data)
+		s.engine.HandleFindMatch(conn, pdu.Data)
 	case "get_game":
 		game.HandleGetGame(conn, pdu.Data)
 	case "attack":
@@ -109,20 +320,54 @@ func processMessage(conn *websocket.Conn, pdu utils.Message) {
 		game.HandleHeal(conn, pdu.Data)
 	case "skip_turn":
 		game.HandleSkipTurn(conn, pdu.Data)
+	case "boost":
+		game.HandleBoost(conn, pdu.Data)
+	case "fortify":
+		game.HandleFortify(conn, pdu.Data)
+	case "swap_card":
+		game.HandleSwapCard(conn, pdu.Data)
 	case "play_again":
 		game.HandlePlayAgain(conn, pdu.Data)
 	case "leave_game":
 		game.HandleLeaveGame(conn, pdu.Data)
 	case "select_troop":
 		game.HandleSelectTroop(conn, pdu.Data)
+	case "spectate":
+		game.HandleSpectate(conn, pdu.Data)
+	case "stop_spectating":
+		game.HandleStopSpectating(conn, pdu.Data)
+	case "list_live_matches":
+		game.HandleListLiveMatches(conn, pdu.Data)
+	case "chat":
+		game.HandleChat(conn, pdu.Data)
+	case "emote":
+		game.HandleEmote(conn, pdu.Data)
+	case "toggle_mute":
+		game.HandleToggleMute(conn, pdu.Data)
+	case "send_friend_request":
+		game.HandleSendFriendRequest(conn, pdu.Data)
+	case "respond_friend_request":
+		game.HandleRespondFriendRequest(conn, pdu.Data)
+	case "remove_friend":
+		game.HandleRemoveFriend(conn, pdu.Data)
+	case "get_friends":
+		game.HandleGetFriends(conn, pdu.Data)
+	case "challenge_friend":
+		s.engine.HandleChallengeFriend(conn, pdu.Data)
+	case "respond_challenge":
+		s.engine.HandleRespondChallenge(conn, pdu.Data)
+	case "report_player":
+		game.HandleReportPlayer(conn, pdu.Data)
 	default:
 		log.Printf("[WARN][WS] Unknown message type: %s", pdu.Type)
 		sendError(conn, "Unknown message type")
+		return false
 	}
+	return true
 }
 
 func sendError(conn *websocket.Conn, message string) {
-	err := conn.WriteJSON(utils.Response{
+	err := utils.WriteMessage(conn, utils.Response{
 		Type:    "error",
 		Success: false,
 		Message: message,
@@ -132,6 +377,13 @@ func sendError(conn *websocket.Conn, message string) {
 	}
 }
 
+func encodingName(conn *websocket.Conn) string {
+	if utils.UsesMsgPack(conn) {
+		return "msgpack"
+	}
+	return "json"
+}
+
 func logWebSocketError(err error) {
 	if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 		log.Printf("[ERROR][WS] Unexpected closure: %v", err)
@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 // Session store
@@ -17,17 +18,24 @@ type Session struct {
 	Authenticated bool   `json:"authenticated"`
 }
 
-var sessionFilePath = "assets/data/sessions.json"
+// SessionStore keeps the sessions in a JSON file in the data directory.
+type SessionStore struct {
+	path string
+}
+
+func NewSessionStore(dataDir string) *SessionStore {
+	return &SessionStore{path: filepath.Join(dataDir, "sessions.json")}
+}
 
 // ReadSessions đọc tất cả các session từ file JSON
-func ReadSessions() ([]Session, error) {
+func (st *SessionStore) ReadSessions() ([]Session, error) {
 	var sessions []Session
-	if _, err := os.Stat(sessionFilePath); os.IsNotExist(err) {
+	if _, err := os.Stat(st.path); os.IsNotExist(err) {
 		log.Println("[WARN][SESSION] Session file not found")
 		return sessions, nil
 	}
 
-	file, err := os.Open(sessionFilePath)
+	file, err := os.Open(st.path)
 	if err != nil {
 		log.Printf("[ERROR][SESSION] Failed to open file: %v", err)
 		return sessions, err
@@ -48,8 +56,8 @@ func ReadSessions() ([]Session, error) {
 }
 
 // ReadSession reads the session data from the file
-func ReadSession(sessionID string) (Session, error) {
-	sessions, err := ReadSessions()
+func (st *SessionStore) ReadSession(sessionID string) (Session, error) {
+	sessions, err := st.ReadSessions()
 	if err != nil {
 		return Session{}, err
 	}
@@ -61,12 +69,12 @@ func ReadSession(sessionID string) (Session, error) {
 		}
 	}
 
-	log.Printf("[WARN][SESSION] Session ID %s not found", sessionID)
-	return Session{}, fmt.Errorf("session with ID %s not found", sessionID)
+	log.Printf("[WARN][SESSION] Session ID not found")
+	return Session{}, fmt.Errorf("session not found")
 }
 
 // WriteSession writes the session data to the file
-func WriteSession(sessions []Session) error {
+func (st *SessionStore) WriteSession(sessions []Session) error {
 	// Deduplicate by username
 	latest := make(map[string]Session)
 	for _, s := range sessions {
@@ -77,7 +85,7 @@ func WriteSession(sessions []Session) error {
 		uniqueSessions = append(uniqueSessions, s)
 	}
 
-	file, err := os.OpenFile(sessionFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	file, err := os.OpenFile(st.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		log.Printf("[ERROR][SESSION] Failed to open file for writing: %v", err)
 		return err
@@ -92,8 +100,8 @@ func WriteSession(sessions []Session) error {
 	return nil
 }
 
-func FindSessionByID(sessionID string) (Session, error) {
-	file, err := os.Open(sessionFilePath)
+func (st *SessionStore) FindSessionByID(sessionID string) (Session, error) {
+	file, err := os.Open(st.path)
 	if err != nil {
 		log.Printf("[ERROR][SESSION] Could not open file: %v", err)
 		return Session{}, err
@@ -113,6 +121,6 @@ func FindSessionByID(sessionID string) (Session, error) {
 		}
 	}
 
-	log.Printf("[WARN][SESSION] Session ID %s not found", sessionID)
+	log.Printf("[WARN][SESSION] Session ID not found")
 	return Session{}, fmt.Errorf("session not found")
 }
@@ -0,0 +1,95 @@
+// internal/utils/codec.go
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Wire encodings a client can negotiate through the Sec-WebSocket-Protocol
+// header when connecting. Clients that request nothing get JSON.
+const (
+	SubprotocolJSON    = "royaka.json"
+	SubprotocolMsgPack = "royaka.msgpack"
+)
+
+// Subprotocols lists the encodings offered by the server, in order of preference.
+var Subprotocols = []string{SubprotocolMsgPack, SubprotocolJSON}
+
+// UsesMsgPack reports whether the connection negotiated MessagePack framing.
+func UsesMsgPack(conn *websocket.Conn) bool {
+	return conn.Subprotocol() == SubprotocolMsgPack
+}
+
+// WriteMessage encodes v with the connection's negotiated encoding.
+// JSON is sent as text frames and MessagePack as binary frames, both using
+// the same field names as the JSON tags of Message and Response.
+func WriteMessage(conn *websocket.Conn, v any) error {
+	_, err := WriteMessageSize(conn, v)
+	return err
+}
+
+// WriteMessageSize is WriteMessage that also reports the encoded size.
+func WriteMessageSize(conn *websocket.Conn, v any) (int, error) {
+	if !UsesMsgPack(conn) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return 0, err
+		}
+		return len(data), conn.WriteMessage(websocket.TextMessage, data)
+	}
+
+	data, err := MarshalMsgPack(v)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// DecodeMessage parses an incoming frame into a Message. Binary frames are
+// read as MessagePack and their data re-encoded to JSON so handlers keep
+// unmarshalling json.RawMessage regardless of the wire format.
+func DecodeMessage(frameType int, raw []byte) (Message, error) {
+	var pdu Message
+
+	if frameType != websocket.BinaryMessage {
+		err := json.Unmarshal(raw, &pdu)
+		return pdu, err
+	}
+
+	var packed struct {
+		Type string `msgpack:"type"`
+		Data any    `msgpack:"data"`
+	}
+	if err := msgpack.Unmarshal(raw, &packed); err != nil {
+		return pdu, err
+	}
+
+	data, err := json.Marshal(packed.Data)
+	if err != nil {
+		return pdu, fmt.Errorf("re-encode msgpack data: %w", err)
+	}
+
+	pdu.Type = packed.Type
+	pdu.Data = data
+	return pdu, nil
+}
+
+// MarshalMsgPack encodes v as compact MessagePack, honouring json struct tags.
+func MarshalMsgPack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,153 @@
+// internal/utils/logging.go
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secret values in log output.
+const Redacted = "[REDACTED]"
+
+// secretKeys are attribute and JSON field names whose values are never
+// logged.
+var secretKeys = []string{"password", "old_password", "new_password", "session_id", "token", "authorization"}
+
+var logLevel = new(slog.LevelVar)
+
+// SetupLogging installs a structured logger writing text or JSON lines at
+// level (debug, info, warn or error) and above. Lines from the standard log
+// package tagged "[LEVEL][AREA] ..." go through the same logger, with the
+// level and area taken from the tags.
+func SetupLogging(level, format string) {
+	logLevel.Set(ParseLogLevel(level))
+
+	opts := &slog.HandlerOptions{Level: logLevel, ReplaceAttr: redactAttr}
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+
+	log.SetFlags(0)
+	log.SetOutput(taggedWriter{handler: handler})
+}
+
+// SetLogLevel changes the level of the running logger.
+func SetLogLevel(level string) {
+	logLevel.Set(ParseLogLevel(level))
+}
+
+func ParseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+func isSecret(key string) bool {
+	return slices.Contains(secretKeys, strings.ToLower(key))
+}
+
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if isSecret(a.Key) && a.Value.String() != "" {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// RedactJSON returns a JSON document with the values of secret fields
+// replaced, at any depth, for logging client messages.
+func RedactJSON(raw []byte) string {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return fmt.Sprintf("<%d bytes, not JSON>", len(raw))
+	}
+	out, _ := json.Marshal(redactValue(v))
+	return string(out)
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSecret(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
+
+// ==== LEGACY LOG LINES ====
+
+var levelTags = map[string]slog.Level{
+	"DEBUG": slog.LevelDebug,
+	"INFO":  slog.LevelInfo,
+	"WARN":  slog.LevelWarn,
+	"ERROR": slog.LevelError,
+}
+
+// taggedWriter turns "[LEVEL][AREA] message" lines from the log package
+// into records; lines without a level tag count as info.
+type taggedWriter struct {
+	handler slog.Handler
+}
+
+func (w taggedWriter) Write(p []byte) (int, error) {
+	level, area, msg := parseTaggedLine(string(p))
+
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, level) {
+		return len(p), nil
+	}
+
+	record := slog.NewRecord(time.Now(), level, msg, 0)
+	if area != "" {
+		record.AddAttrs(slog.String("area", area))
+	}
+	if err := w.handler.Handle(ctx, record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func parseTaggedLine(line string) (slog.Level, string, string) {
+	level := slog.LevelInfo
+	var areas []string
+
+	rest := strings.TrimSpace(line)
+	for strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			break
+		}
+		tag := rest[1:end]
+		if l, ok := levelTags[tag]; ok {
+			level = l
+		} else {
+			areas = append(areas, strings.ToLower(tag))
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return level, strings.Join(areas, "/"), rest
+}
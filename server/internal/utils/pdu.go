@@ -28,6 +28,12 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type ClaimAccountRequest struct {
+	SessionID string `json:"session_id"` // the guest's, from guest_login
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
 type UserRequest struct {
 	SessionID string `json:"session_id"`
 }
@@ -35,34 +41,155 @@ type UserRequest struct {
 type FindMatchRequest struct {
 	Username string `json:"username"`
 	Mode     string `json:"mode"`
+	Deck     string `json:"deck,omitempty"` // saved deck name; defaults to the mode's active deck
 }
 
 type GameRequest struct {
-	RoomID   string `json:"room_id"`
-	Username string `json:"username"`
+	RoomID string `json:"room_id"`
 }
 
 type AttackRequest struct {
-	RoomID   string `json:"room_id"`
-	Username string `json:"username"`
-	Troop    string `json:"troop"`
-	Target   string `json:"target"`
+	RoomID string `json:"room_id"`
+	Troop  string `json:"troop"`
+	Target string `json:"target"`
 }
 
 type SelectTroopRequest struct {
-	RoomID   string  `json:"room_id"`
-	Username string  `json:"username"`
-	Troop    string  `json:"troop"`
-	X        float64 `json:"x"`
-	Y        float64 `json:"y"`
+	RoomID string  `json:"room_id"`
+	Troop  string  `json:"troop"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
 }
 
 type HealRequest struct {
-	RoomID   string `json:"room_id"`
-	Username string `json:"username"`
-	Troop    string `json:"troop"`
+	RoomID string `json:"room_id"`
+	Troop  string `json:"troop"`
+}
+
+// TacticRequest is a boost, fortify or swap_card. Boost and swap_card name
+// a troop in hand, fortify names one of the player's own towers as target.
+type TacticRequest struct {
+	RoomID string `json:"room_id"`
+	Troop  string `json:"troop,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+type SpectateRequest struct {
+	RoomID string `json:"room_id"`
+	Delay  int    `json:"delay"` // seconds, capped server-side
+}
+
+type ChatRequest struct {
+	RoomID  string `json:"room_id"` // empty for the lobby channel
+	Message string `json:"message"`
+}
+
+type EmoteRequest struct {
+	RoomID string `json:"room_id"`
+	Emote  string `json:"emote"`
+}
+
+type MuteRequest struct {
+	Target string `json:"target"`
+}
+
+type FriendRequest struct {
+	Target string `json:"target"`
+	Accept bool   `json:"accept,omitempty"`
+}
+
+type ChallengeRequest struct {
+	Target string          `json:"target"`
+	Mode   string          `json:"mode"`
+	Rules  json.RawMessage `json:"rules,omitempty"` // overrides of the mode's match rules
+}
+
+type ChallengeResponseRequest struct {
+	Challenger string `json:"challenger"`
+	Accept     bool   `json:"accept"`
 }
 
 type GameOverRequest struct {
 	RoomID string `json:"room_id"`
 }
+
+type UpgradeCardRequest struct {
+	Card string `json:"card"`
+}
+
+type DeckRequest struct {
+	Name  string   `json:"name"`
+	Cards []string `json:"cards,omitempty"`
+	Mode  string   `json:"mode,omitempty"`
+}
+
+type ShopRequest struct {
+	OfferID string `json:"offer_id"`
+}
+
+type ChestRequest struct {
+	ChestID string `json:"chest_id"`
+}
+
+type MatchHistoryRequest struct {
+	Username string `json:"username"` // defaults to the requesting user
+	Offset   int    `json:"offset"`
+	Limit    int    `json:"limit"`
+}
+
+type LeaderboardRequest struct {
+	Scope  string `json:"scope"` // "global" (default) or "friends"
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangeAvatarRequest struct {
+	Avatar string `json:"avatar"`
+}
+
+type ChangeUsernameRequest struct {
+	NewUsername string `json:"new_username"`
+	Password    string `json:"password"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Confirm  string `json:"confirm"` // must repeat the username
+}
+
+type ReportPlayerRequest struct {
+	MatchID string `json:"match_id"`
+	Reason  string `json:"reason"` // cheating, abusive_chat, afk, unsportsmanlike or other
+	Details string `json:"details,omitempty"`
+}
+
+// ==== ADMIN API ====
+
+type EndMatchRequest struct {
+	Winner string `json:"winner,omitempty"` // empty ends the match as a draw
+}
+
+type KickRequest struct {
+	Reason string `json:"reason"`
+}
+
+type BanRequest struct {
+	Reason   string `json:"reason"`
+	Duration string `json:"duration,omitempty"` // Go duration, empty for permanent; required to suspend
+}
+
+type ResolveReportRequest struct {
+	Action   string `json:"action"`             // dismiss, none, suspend or ban
+	Duration string `json:"duration,omitempty"` // for suspend and ban, as in BanRequest
+	Note     string `json:"note,omitempty"`
+}
+
+type AnnouncementRequest struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind,omitempty"` // info (default), warning or maintenance
+}
@@ -1,26 +1,102 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"royaka/internal/config"
+	"royaka/internal/game"
+	"royaka/internal/model"
 	"royaka/internal/network"
+	"royaka/internal/utils"
+	"syscall"
+	"time"
+)
+
+const (
+	// closeTimeout is how long clients get to answer the WebSocket close frame.
+	closeTimeout = 2 * time.Second
+	// settleDelay lets the results of ended matches reach the players
+	// before their connections are closed.
+	settleDelay = 250 * time.Millisecond
 )
 
 func main() {
-	// cfg := config.LoadConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("[ERROR][CONFIG] Invalid configuration:\n%v", err)
+	}
+
+	utils.SetupLogging(cfg.LogLevel, cfg.LogFormat)
+	model.SetDataDir(cfg.DataDir)
+	engine := game.NewEngine(cfg)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	snapshots := cfg.SnapshotInterval > 0
+	if snapshots {
+		if restored := engine.RestoreSnapshots(); restored > 0 {
+			log.Printf("[INFO][SNAPSHOT] %d matches waiting for their players", restored)
+		}
+		game.StartSnapshots(time.Duration(cfg.SnapshotInterval))
 	}
 
-	fs := http.FileServer(http.Dir("./assets"))
-	http.Handle("/assets/", http.StripPrefix("/assets/", fs))
+	server := network.NewServer(cfg, engine)
+	httpServer := &http.Server{Addr: cfg.ListenAddr, Handler: server.Handler()}
+
+	game.StartSeasonScheduler()
+	if cfg.GuestTTL > 0 {
+		server.StartGuestCleanup(time.Duration(cfg.GuestTTL))
+	}
+
+	go func() {
+		log.Printf("[INFO][SERVER] Running at %s (data: %s, storage: %s, log level: %s)",
+			cfg.ListenAddr, cfg.DataDir, cfg.Storage, cfg.LogLevel)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
 
-	// WebSocket handler
-	http.HandleFunc("/ws", network.HandleWebSocket)
+	shutdown(httpServer, server, time.Duration(cfg.ShutdownGrace), snapshots)
+}
+
+// shutdown stops new matches, gives running ones the grace period to end,
+// saves the rest for the next start (or records them as ended by the
+// shutdown when snapshots are off) and closes every connection.
+// A second signal during the grace period skips the wait.
+func shutdown(httpServer *http.Server, server *network.Server, grace time.Duration, snapshots bool) {
+	deadline := time.Now().Add(grace)
+	log.Printf("[INFO][SHUTDOWN] Signal received, waiting up to %s for matches", grace)
+	game.BeginShutdown(deadline)
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if live := game.WaitForMatches(ctx); live > 0 {
+		if snapshots {
+			log.Printf("[WARN][SHUTDOWN] Saving %d unfinished matches for restart", live)
+			game.SuspendMatches()
+		} else {
+			log.Printf("[WARN][SHUTDOWN] Ending %d unfinished matches", live)
+			game.AbortMatches()
+		}
+		time.Sleep(settleDelay)
+	}
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer closeCancel()
+	if err := httpServer.Shutdown(closeCtx); err != nil {
+		log.Printf("[ERROR][SHUTDOWN] HTTP shutdown: %v", err)
+	}
+	server.CloseConnections(closeTimeout)
 
-	log.Println("Server running at http://localhost:" + port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	model.Flush()
+	log.Println("[INFO][SHUTDOWN] Server stopped")
 }